@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// guessBasicAuth decodes HTTP Basic authentication as in the header
+// "Authorization: Basic dXNlcjpwYXNz".  The password is only shown with
+// -verbose, so that guesses can be shared without leaking it.
+func guessBasicAuth(s string) []Guess {
+	s = strings.TrimSpace(strings.TrimPrefix(s, "Authorization:"))
+	scheme, creds, ok := strings.Cut(s, " ")
+	if !ok || !strings.EqualFold(scheme, "Basic") {
+		return nil
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(creds))
+	if err != nil {
+		trace("cannot decode Basic credentials: %v", err)
+		return nil
+	}
+	user, pass, ok := strings.Cut(string(b), ":")
+	if !ok {
+		return nil
+	}
+	return []Guess{{
+		guess:      fmt.Sprintf("HTTP Basic credentials for user %q", user),
+		additional: []string{"password: " + maskPassword(pass)},
+		source:     "HTTP Basic authentication",
+		goodness:   200,
+	}}
+}
+
+func maskPassword(pass string) string {
+	if *verbose {
+		return fmt.Sprintf("%q", pass)
+	}
+	return fmt.Sprintf("%s (%d characters, shown with -verbose)", strings.Repeat("*", 8), len([]rune(pass)))
+}
+
+// guessUserPass shows what user:pass looks like as HTTP Basic credentials.
+// Lots of things have a colon in them, so this is only a remote
+// possibility unless the user name looks like one.
+func guessUserPass(s string) []Guess {
+	user, pass, ok := strings.Cut(s, ":")
+	if !ok || user == "" || pass == "" || strings.HasPrefix(pass, "//") || strings.ContainsAny(s, " \t") {
+		return nil
+	}
+	if !unicode.IsLetter([]rune(user)[0]) {
+		return nil
+	}
+	header := "Basic " + base64.StdEncoding.EncodeToString([]byte(s))
+	if !*verbose {
+		header = "Basic " + strings.Repeat("*", 8) + " (shown with -verbose)"
+	}
+	return []Guess{{
+		guess:      fmt.Sprintf("User %q with a password", user),
+		additional: []string{"password: " + maskPassword(pass), "as HTTP header: Authorization: " + header},
+		source:     "user:password",
+		goodness:   10,
+	}}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGuessBasicAuth(t *testing.T) {
+	for _, in := range []string{"Basic dXNlcjpzM2NyZXQ=", "Authorization: Basic dXNlcjpzM2NyZXQ=", "basic dXNlcjpzM2NyZXQ="} {
+		gs := guessBasicAuth(in)
+		want := []string{"password: ******** (6 characters, shown with -verbose)"}
+		if len(gs) != 1 || gs[0].guess != `HTTP Basic credentials for user "user"` || !reflect.DeepEqual(gs[0].additional, want) {
+			t.Errorf("guessBasicAuth(%q) = %+v", in, gs)
+		}
+	}
+	setFlag(t, "verbose", "true")
+	if gs := guessBasicAuth("Basic dXNlcjpzM2NyZXQ="); len(gs) != 1 || gs[0].additional[0] != `password: "s3cret"` {
+		t.Errorf("guessBasicAuth() with -verbose = %+v", gs)
+	}
+	for _, s := range []string{"Basic", "Bearer dXNlcjpzM2NyZXQ=", "Basic !!!", "Basic dXNlcg=="} {
+		if gs := guessBasicAuth(s); gs != nil {
+			t.Errorf("guessBasicAuth(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessUserPass(t *testing.T) {
+	gs := guessUserPass("user:s3cret")
+	want := []string{"password: ******** (6 characters, shown with -verbose)", "as HTTP header: Authorization: Basic ******** (shown with -verbose)"}
+	if len(gs) != 1 || gs[0].guess != `User "user" with a password` || !reflect.DeepEqual(gs[0].additional, want) {
+		t.Errorf("guessUserPass(user:s3cret) = %+v", gs)
+	}
+	setFlag(t, "verbose", "true")
+	if gs := guessUserPass("user:s3cret"); len(gs) != 1 || gs[0].additional[1] != "as HTTP header: Authorization: Basic dXNlcjpzM2NyZXQ=" {
+		t.Errorf("guessUserPass(user:s3cret) with -verbose = %+v", gs)
+	}
+	for _, s := range []string{"12:30", "http://example.com", "user:", ":pass", "a b:c", "::1"} {
+		if gs := guessUserPass(s); gs != nil {
+			t.Errorf("guessUserPass(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Byte sequences like "08 96 01", "0x08 0x96 0x01" or "\x08\x96\x01".
+var (
+	spacedBytesRE  = regexp.MustCompile(`^(?:0x)?[0-9a-fA-F]{2}(?:[ ,]+(?:0x)?[0-9a-fA-F]{2})+$`)
+	escapedBytesRE = regexp.MustCompile(`^(?:\\x[0-9a-fA-F]{2}){2,}$`)
+)
+
+// parseByteSequence returns the bytes in s if it looks like a sequence of
+// bytes written in hex.
+func parseByteSequence(s string) []byte {
+	var digits string
+	switch {
+	case spacedBytesRE.MatchString(s):
+		digits = strings.NewReplacer("0x", "", " ", "", ",", "").Replace(s)
+	case escapedBytesRE.MatchString(s):
+		digits = strings.ReplaceAll(s, `\x`, "")
+	default:
+		return nil
+	}
+	b, err := hex.DecodeString(digits)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// decodeVarints decodes b as a sequence of protobuf/LEB128 varints, or
+// returns nil if b doesn't end with a complete one.
+func decodeVarints(b []byte) []uint64 {
+	var vs []uint64
+	for len(b) > 0 {
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil
+		}
+		vs = append(vs, v)
+		b = b[n:]
+	}
+	return vs
+}
+
+// decodeProtobuf decodes b as protobuf wire format without a schema, or
+// returns nil if it isn't valid.
+func decodeProtobuf(b []byte) []string {
+	var fields []string
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 || tag>>3 == 0 {
+			return nil
+		}
+		b = b[n:]
+		field := tag >> 3
+		switch tag & 7 {
+		case 0:
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				return nil
+			}
+			fields = append(fields, fmt.Sprintf("field %d (varint) = %d", field, v))
+			b = b[n:]
+		case 1:
+			if len(b) < 8 {
+				return nil
+			}
+			fields = append(fields, fmt.Sprintf("field %d (64-bit) = %d", field, binary.LittleEndian.Uint64(b)))
+			b = b[8:]
+		case 2:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				return nil
+			}
+			fields = append(fields, fmt.Sprintf("field %d (%d bytes) = %s", field, l, strconv.Quote(string(b[n:n+int(l)]))))
+			b = b[n+int(l):]
+		case 5:
+			if len(b) < 4 {
+				return nil
+			}
+			fields = append(fields, fmt.Sprintf("field %d (32-bit) = %d", field, binary.LittleEndian.Uint32(b)))
+			b = b[4:]
+		default:
+			return nil
+		}
+	}
+	return fields
+}
+
+// guessByteSequence interprets a sequence of hex bytes as integers in both
+// byte orders, as varints and as protobuf message.
+func guessByteSequence(s string) []Guess {
+	b := parseByteSequence(s)
+	if b == nil {
+		return nil
+	}
+	var lines []string
+	if len(b) <= 8 {
+		var be, le uint64
+		for i := range b {
+			be = be<<8 | uint64(b[i])
+			le = le<<8 | uint64(b[len(b)-1-i])
+		}
+		lines = append(lines, fmt.Sprintf("big-endian integer: %d", be))
+		lines = append(lines, fmt.Sprintf("little-endian integer: %d", le))
+	}
+	if vs := decodeVarints(b); vs != nil {
+		var ss []string
+		for _, v := range vs {
+			ss = append(ss, strconv.FormatUint(v, 10))
+		}
+		lines = append(lines, "varints: "+strings.Join(ss, ", "))
+	}
+	if fields := decodeProtobuf(b); fields != nil {
+		for _, f := range fields {
+			lines = append(lines, "protobuf "+f)
+		}
+	}
+	return []Guess{{
+		guess:      fmt.Sprintf("Byte sequence % x", b),
+		comment:    fmt.Sprintf("%d bytes", len(b)),
+		additional: lines,
+		source:     "byte sequence",
+		goodness:   100,
+	}}
+}
+
+// guessHexInt interprets 0x-prefixed hex numbers as integers, and shows
+// them with their bytes swapped for the natural width of 2, 4 or 8 bytes
+// the digits fit into.
+func guessHexInt(s string) []Guess {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return nil
+	}
+	digits := s[2:]
+	if !isHex(digits) || len(digits) > 16 {
+		return nil
+	}
+	n, err := strconv.ParseUint(digits, 16, 64)
+	if err != nil {
+		return nil
+	}
+	var lines []string
+	width := 8
+	switch {
+	case len(digits) <= 2:
+		width = 1
+	case len(digits) <= 4:
+		width = 2
+	case len(digits) <= 8:
+		width = 4
+	}
+	bits := 8 * width
+	if signed := int64(n<<(64-bits)) >> (64 - bits); signed < 0 {
+		lines = append(lines, fmt.Sprintf("as signed %d-bit integer: %d", bits, signed))
+	}
+	if width > 1 {
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, n)
+		b = b[8-width:]
+		var swapped uint64
+		for i := len(b) - 1; i >= 0; i-- {
+			swapped = swapped<<8 | uint64(b[i])
+		}
+		lines = append(lines, fmt.Sprintf("byte-swapped as %d-bit integer: 0x%0*x = %d", bits, 2*width, swapped, swapped))
+	}
+	return []Guess{{
+		guess:      fmt.Sprintf("Hexadecimal integer %d", n),
+		comment:    s,
+		additional: lines,
+		source:     "hexadecimal integer",
+		goodness:   150,
+	}}
+}
+
+// guessBCD reads 0x-prefixed hex as binary-coded decimal, one decimal digit
+// per nibble, as used by real-time clock chips, and as packed decimal with
+// a trailing sign nibble C, D or F, as in COBOL's COMP-3.
+func guessBCD(s string) []Guess {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return nil
+	}
+	digits := strings.ToLower(s[2:])
+	if !isHex(digits) {
+		return nil
+	}
+	if strings.Trim(digits, "0123456789") == "" {
+		return []Guess{{
+			guess:    "Binary-coded decimal " + strings.TrimLeft(digits[:len(digits)-1], "0") + digits[len(digits)-1:],
+			source:   "BCD",
+			goodness: 20,
+		}}
+	}
+	body, sign := digits[:len(digits)-1], digits[len(digits)-1]
+	if len(body) > 0 && strings.Trim(body, "0123456789") == "" && strings.IndexByte("cdf", sign) >= 0 {
+		n := strings.TrimLeft(body[:len(body)-1], "0") + body[len(body)-1:]
+		if sign == 'd' {
+			n = "-" + n
+		}
+		return []Guess{{
+			guess:    "Packed decimal " + n,
+			comment:  fmt.Sprintf("sign nibble %c", sign),
+			source:   "packed BCD",
+			goodness: 20,
+		}}
+	}
+	var bad []string
+	for i, c := range digits {
+		if c > '9' {
+			bad = append(bad, fmt.Sprintf("%c at digit %d", c, i+1))
+		}
+	}
+	return []Guess{{
+		guess:    "Not binary-coded decimal",
+		comment:  "invalid nibbles " + strings.Join(bad, ", "),
+		source:   "BCD",
+		goodness: -10,
+	}}
+}
+
+// guessGrayCode converts 0b-prefixed binary numbers and decimal integers to
+// and from the reflected binary Gray code of rotary encoders, where
+// neighbouring values differ in a single bit.  Decimal integers are only
+// converted up to 16 bits, the width of encoders in practice, and only as
+// unlikely guesses, to keep the noise down for all the other numbers.
+func guessGrayCode(s string) []Guess {
+	base, digits := 10, s
+	if strings.HasPrefix(s, "0b") || strings.HasPrefix(s, "0B") {
+		base, digits = 2, s[2:]
+	}
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return nil
+	}
+	n, err := strconv.ParseUint(digits, base, 64)
+	if err != nil || n < 2 || base == 10 && n > 0xffff {
+		return nil
+	}
+	gray := n ^ n>>1
+	plain := n
+	for shift := n >> 1; shift != 0; shift >>= 1 {
+		plain ^= shift
+	}
+	g := Guess{
+		guess: fmt.Sprintf("Gray code conversion of %d", n),
+		additional: []string{
+			fmt.Sprintf("binary 0b%b in Gray code: 0b%b = %d", n, gray, gray),
+			fmt.Sprintf("Gray code 0b%b in binary: 0b%b = %d", n, plain, plain),
+		},
+		source:   "Gray code",
+		goodness: -10,
+	}
+	if base == 2 {
+		g.comment = s
+		g.goodness = 50
+	}
+	return []Guess{g}
+}
+
+// guessOtherBases reads decimal-looking integers as binary, octal and
+// hexadecimal numbers whose prefix may have been forgotten, like 101 for
+// 0b101.  This is only done with -bases, as almost every number would get
+// a hexadecimal reading otherwise.
+func guessOtherBases(s string) []Guess {
+	if !*otherBases || len(s) < 2 || strings.Trim(s, "0123456789") != "" {
+		return nil
+	}
+	var gs []Guess
+	for _, b := range []struct {
+		base   int
+		digits string
+		name   string
+		prefix string
+	}{
+		{2, "01", "Binary", "0b"},
+		{8, "01234567", "Octal", "0o"},
+		{16, "0123456789", "Hexadecimal", "0x"},
+	} {
+		if strings.Trim(s, b.digits) != "" {
+			continue
+		}
+		n, err := strconv.ParseUint(s, b.base, 64)
+		if err != nil {
+			continue
+		}
+		gs = append(gs, Guess{
+			guess:    fmt.Sprintf("%s %s is %d", b.name, s, n),
+			comment:  "if " + b.prefix + s + " was meant",
+			source:   strings.ToLower(b.name) + " number without prefix",
+			goodness: 5,
+		})
+	}
+	return gs
+}
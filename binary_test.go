@@ -0,0 +1,139 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGuessByteSequence(t *testing.T) {
+	for _, in := range []string{"08 96 01", "0x08 0x96 0x01", `\x08\x96\x01`, "08, 96, 01"} {
+		gs := guessByteSequence(in)
+		want := []string{
+			"big-endian integer: 562689",
+			"little-endian integer: 103944",
+			"varints: 8, 150",
+			"protobuf field 1 (varint) = 150",
+		}
+		if len(gs) != 1 || gs[0].guess != "Byte sequence 08 96 01" || !reflect.DeepEqual(gs[0].additional, want) {
+			t.Errorf("guessByteSequence(%q) = %+v, want %q", in, gs, want)
+		}
+	}
+
+	gs := guessByteSequence("12 02 68 69")
+	if len(gs) != 1 || gs[0].additional[3] != `protobuf field 2 (2 bytes) = "hi"` {
+		t.Errorf("guessByteSequence(12 02 68 69) = %+v", gs)
+	}
+	gs = guessByteSequence("ff ff")
+	if len(gs) != 1 || len(gs[0].additional) != 2 {
+		t.Errorf("guessByteSequence(ff ff) = %+v, want no varint or protobuf lines", gs)
+	}
+
+	for _, s := range []string{"08", "089601", "08 9", "zz yy", `\x08`, "08:96:01"} {
+		if gs := guessByteSequence(s); gs != nil {
+			t.Errorf("guessByteSequence(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessHexInt(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess string
+		lines     []string
+	}{
+		{"0x1234", "Hexadecimal integer 4660", []string{"byte-swapped as 16-bit integer: 0x3412 = 13330"}},
+		{"0xff", "Hexadecimal integer 255", []string{"as signed 8-bit integer: -1"}},
+		{"0x12345", "Hexadecimal integer 74565", []string{"byte-swapped as 32-bit integer: 0x45230100 = 1159921920"}},
+		{"0XFFFFFFFE", "Hexadecimal integer 4294967294", []string{
+			"as signed 32-bit integer: -2",
+			"byte-swapped as 32-bit integer: 0xfeffffff = 4278190079",
+		}},
+		{"0x0102030405060708", "Hexadecimal integer 72623859790382856", []string{"byte-swapped as 64-bit integer: 0x0807060504030201 = 578437695752307201"}},
+	} {
+		gs := guessHexInt(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess || !reflect.DeepEqual(gs[0].additional, tc.lines) {
+			t.Errorf("guessHexInt(%q) = %+v, want %q, %q", tc.in, gs, tc.guess, tc.lines)
+		}
+	}
+	for _, s := range []string{"0x", "1234", "0xgg", "0x10000000000000000"} {
+		if gs := guessHexInt(s); gs != nil {
+			t.Errorf("guessHexInt(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessBCD(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess, comment string
+		goodness           int
+	}{
+		{"0x1234", "Binary-coded decimal 1234", "", 20},
+		{"0x0059", "Binary-coded decimal 59", "", 20},
+		{"0x00", "Binary-coded decimal 0", "", 20},
+		{"0x12345C", "Packed decimal 12345", "sign nibble c", 20},
+		{"0x12345d", "Packed decimal -12345", "sign nibble d", 20},
+		{"0x0f", "Packed decimal 0", "sign nibble f", 20},
+		{"0x12a4", "Not binary-coded decimal", "invalid nibbles a at digit 3", -10},
+		{"0xcafe", "Not binary-coded decimal", "invalid nibbles c at digit 1, a at digit 2, f at digit 3, e at digit 4", -10},
+	} {
+		gs := guessBCD(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess || gs[0].comment != tc.comment || gs[0].goodness != tc.goodness {
+			t.Errorf("guessBCD(%q) = %+v, want %q (%s) with goodness %d", tc.in, gs, tc.guess, tc.comment, tc.goodness)
+		}
+	}
+	for _, s := range []string{"1234", "0x", "0xzz"} {
+		if gs := guessBCD(s); gs != nil {
+			t.Errorf("guessBCD(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessGrayCode(t *testing.T) {
+	for _, tc := range []struct {
+		in       string
+		want     []string
+		goodness int
+	}{
+		{"0b1101", []string{"binary 0b1101 in Gray code: 0b1011 = 11", "Gray code 0b1101 in binary: 0b1001 = 9"}, 50},
+		{"13", []string{"binary 0b1101 in Gray code: 0b1011 = 11", "Gray code 0b1101 in binary: 0b1001 = 9"}, -10},
+		{"0b10", []string{"binary 0b10 in Gray code: 0b11 = 3", "Gray code 0b10 in binary: 0b11 = 3"}, 50},
+		{"255", []string{"binary 0b11111111 in Gray code: 0b10000000 = 128", "Gray code 0b11111111 in binary: 0b10101010 = 170"}, -10},
+	} {
+		gs := guessGrayCode(tc.in)
+		if len(gs) != 1 || !reflect.DeepEqual(gs[0].additional, tc.want) || gs[0].goodness != tc.goodness {
+			t.Errorf("guessGrayCode(%q) = %+v, want %q with goodness %d", tc.in, gs, tc.want, tc.goodness)
+		}
+	}
+	for _, s := range []string{"1", "0b", "0b102", "65536", "-3", "1.5", "0x10"} {
+		if gs := guessGrayCode(s); gs != nil {
+			t.Errorf("guessGrayCode(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessOtherBases(t *testing.T) {
+	if gs := guessOtherBases("101"); gs != nil {
+		t.Errorf("guessOtherBases(101) without -bases = %+v, want nil", gs)
+	}
+	setFlag(t, "bases", "true")
+	for _, tc := range []struct {
+		in   string
+		want []string
+	}{
+		{"101", []string{"Binary 101 is 5", "Octal 101 is 65", "Hexadecimal 101 is 257"}},
+		{"777", []string{"Octal 777 is 511", "Hexadecimal 777 is 1911"}},
+		{"1999", []string{"Hexadecimal 1999 is 6553"}},
+	} {
+		var got []string
+		for _, g := range guessOtherBases(tc.in) {
+			got = append(got, g.guess)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("guessOtherBases(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+	for _, s := range []string{"7", "0x10", "-101", "1.5", "12345678901234567890"} {
+		if gs := guessOtherBases(s); gs != nil {
+			t.Errorf("guessOtherBases(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Package bytesize parses and formats byte counts with binary units like
+// KiB and decimal units like KB.
+package bytesize
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// A Unit is a power of 1024 bytes together with the corresponding power of
+// 1000 bytes.
+type Unit struct {
+	Binary      string // e.g. KiB
+	BinaryMult  int64
+	Decimal     string // e.g. KB
+	DecimalMult int64
+	Alias       string // e.g. K, which means the binary unit
+}
+
+// Units lists all units, smallest first.
+var Units = []Unit{
+	{"KiB", 1 << 10, "KB", 1e3, "K"},
+	{"MiB", 1 << 20, "MB", 1e6, "M"},
+	{"GiB", 1 << 30, "GB", 1e9, "G"},
+	{"TiB", 1 << 40, "TB", 1e12, "T"},
+	{"PiB", 1 << 50, "PB", 1e15, "P"},
+	{"EiB", 1 << 60, "EB", 1e18, "E"},
+}
+
+// ErrTooLarge is returned for byte counts that don't fit into an int64,
+// i.e. that are 8 EiB or more.
+var ErrTooLarge = errors.New("bytesize: value too large to represent exactly")
+
+// ParseBytes parses a byte count like 1536, 512 B, 1.5KiB, 1.5 KB or 2G.
+// A bare alias like K means the binary unit.  Fractional counts are
+// computed exactly and rounded to the nearest byte.
+func ParseBytes(s string) (int64, error) {
+	n, err := strconv.ParseInt(strings.TrimSpace(strings.TrimSuffix(s, "B")), 10, 64)
+	if err == nil {
+		return n, nil
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, fmt.Errorf("%w: %s", ErrTooLarge, s)
+	}
+	for _, u := range Units {
+		var mult int64
+		num := s
+		switch {
+		case strings.HasSuffix(s, u.Binary):
+			mult, num = u.BinaryMult, strings.TrimSuffix(s, u.Binary)
+		case strings.HasSuffix(s, u.Decimal):
+			mult, num = u.DecimalMult, strings.TrimSuffix(s, u.Decimal)
+		case strings.HasSuffix(s, u.Alias):
+			mult, num = u.BinaryMult, strings.TrimSuffix(s, u.Alias)
+		default:
+			continue
+		}
+		num = strings.TrimSpace(num)
+		r, ok := new(big.Rat).SetString(num)
+		if !ok || strings.Contains(num, "/") {
+			return 0, fmt.Errorf("bytesize: invalid number in %q", s)
+		}
+		v := roundRat(r.Mul(r, new(big.Rat).SetInt64(mult)))
+		if !v.IsInt64() {
+			return 0, fmt.Errorf("%w: %s", ErrTooLarge, s)
+		}
+		return v.Int64(), nil
+	}
+	return 0, fmt.Errorf("bytesize: cannot parse %q", s)
+}
+
+// roundRat rounds r to the nearest integer, and halves away from zero.
+func roundRat(r *big.Rat) *big.Int {
+	num := new(big.Int).Abs(r.Num())
+	// (2|num| + den) / 2den, truncated
+	num.Add(num.Lsh(num, 1), r.Denom())
+	n := num.Quo(num, new(big.Int).Lsh(r.Denom(), 1))
+	if r.Sign() < 0 {
+		n.Neg(n)
+	}
+	return n
+}
+
+// FormatBytes formats n with the largest binary unit it is at least one of,
+// e.g. 1.5 KiB.  The number is exact, however many digits that takes, so
+// ParseBytes turns it back into n.
+func FormatBytes(n int64) string {
+	return format(n, func(u Unit) (string, int64) { return u.Binary, u.BinaryMult })
+}
+
+// FormatBytesDecimal is like FormatBytes, but uses decimal units like KB.
+func FormatBytesDecimal(n int64) string {
+	return format(n, func(u Unit) (string, int64) { return u.Decimal, u.DecimalMult })
+}
+
+func format(n int64, unit func(Unit) (string, int64)) string {
+	// Without overflow for math.MinInt64.
+	abs := uint64(n)
+	if n < 0 {
+		abs = -abs
+	}
+	for i := len(Units) - 1; i >= 0; i-- {
+		sym, mult := unit(Units[i])
+		if abs >= uint64(mult) {
+			// Powers of 2 and 10 divide into finite decimals of no more
+			// digits than the 60 of 1/2^60.
+			v := new(big.Rat).SetFrac64(n, mult).FloatString(60)
+			v = strings.TrimRight(strings.TrimRight(v, "0"), ".")
+			return v + " " + sym
+		}
+	}
+	return strconv.FormatInt(n, 10) + " B"
+}
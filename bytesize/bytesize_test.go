@@ -0,0 +1,85 @@
+package bytesize
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseBytes(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want int64
+	}{
+		{"1536", 1536},
+		{"512 B", 512},
+		{"1.5KiB", 1536},
+		{"1.5 KB", 1500},
+		{"2G", 2 << 30},
+		{"3MB", 3000000},
+		{"1EiB", 1 << 60},
+		{"0.1GiB", 107374182},
+		{"0.3KiB", 307},
+		{"0.7KiB", 717},
+		{"1.1EB", 1100000000000000000},
+		{"9.2EB", 9200000000000000000},
+		{"0.0005KB", 1},
+		{"0.0004KB", 0},
+		{"-0.0005KB", -1},
+		{"-5MiB", -5 << 20},
+		{"-2 GB", -2e9},
+		{"1e3KB", 1000000},
+	} {
+		if got, err := ParseBytes(tc.in); err != nil || got != tc.want {
+			t.Errorf("ParseBytes(%q) = %d, %v, want %d", tc.in, got, err, tc.want)
+		}
+	}
+	for _, s := range []string{"", "B", "KiB", "1.5", "1.5 XB", "oneMB", "1/2KiB"} {
+		if got, err := ParseBytes(s); err == nil {
+			t.Errorf("ParseBytes(%q) = %d, want error", s, got)
+		}
+	}
+}
+
+func TestParseBytesTooLarge(t *testing.T) {
+	for _, s := range []string{"8EiB", "9.3EB", "-9EiB", "9223372036854775808"} {
+		if got, err := ParseBytes(s); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("ParseBytes(%q) = %d, %v, want ErrTooLarge", s, got, err)
+		}
+	}
+	if got, err := ParseBytes("7.5EiB"); err != nil || got != 15<<59 {
+		t.Errorf("ParseBytes(7.5EiB) = %d, %v", got, err)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for _, tc := range []struct {
+		n               int64
+		binary, decimal string
+	}{
+		{512, "512 B", "512 B"},
+		{1536, "1.5 KiB", "1.536 KB"},
+		{3000000, "2.86102294921875 MiB", "3 MB"},
+		{-2048, "-2 KiB", "-2.048 KB"},
+		{1<<53 + 1, "8.00000000000000088817841970012523233890533447265625 PiB", "9.007199254740993 PB"},
+		{math.MinInt64, "-8 EiB", "-9.223372036854775808 EB"},
+	} {
+		if got := FormatBytes(tc.n); got != tc.binary {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tc.n, got, tc.binary)
+		}
+		if got := FormatBytesDecimal(tc.n); got != tc.decimal {
+			t.Errorf("FormatBytesDecimal(%d) = %q, want %q", tc.n, got, tc.decimal)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, n := range []int64{0, 1, 1000, 1024, 1536, 1500000, 1 << 20, 5 << 30, 3e12, 1 << 50, 1<<53 - 1, 1 << 53, 1<<53 + 1, math.MaxInt64, -math.MaxInt64, math.MinInt64} {
+		for _, s := range []string{FormatBytes(n), FormatBytesDecimal(n)} {
+			got, err := ParseBytes(s)
+			if err != nil || got != n {
+				t.Errorf("ParseBytes(%q) = %d, %v, want %d", s, got, err, n)
+			}
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Commands that print the clipboard, tried in order.
+var clipboardCommands = map[string][][]string{
+	"darwin":  {{"pbpaste"}},
+	"windows": {{"powershell.exe", "-NoProfile", "-Command", "Get-Clipboard"}},
+	"linux": {
+		{"wl-paste", "--no-newline"},
+		{"xclip", "-selection", "clipboard", "-out"},
+		{"xsel", "--clipboard", "--output"},
+	},
+}
+
+// readClipboard returns the contents of the system clipboard.
+func readClipboard() (string, error) {
+	cmds := clipboardCommands[runtime.GOOS]
+	if cmds == nil {
+		// The BSDs and others use the same tools as Linux.
+		cmds = clipboardCommands["linux"]
+	}
+	var names []string
+	for _, c := range cmds {
+		names = append(names, c[0])
+		if _, err := exec.LookPath(c[0]); err != nil {
+			continue
+		}
+		out, err := exec.Command(c[0], c[1:]...).Output()
+		if err != nil {
+			trace("%s failed: %v", c[0], err)
+			continue
+		}
+		return string(out), nil
+	}
+	return "", fmt.Errorf("cannot read the clipboard with any of %s", strings.Join(names, ", "))
+}
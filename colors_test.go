@@ -0,0 +1,40 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGuessCSSColor(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess  string
+		additional []string
+	}{
+		{"rebeccapurple", "CSS color rebeccapurple is #663399", []string{"rgb(102, 51, 153)"}},
+		{"Tomato", "CSS color tomato is #ff6347", []string{"rgb(255, 99, 71)"}},
+		{"#FF6347", "CSS color #ff6347 is rgb(255, 99, 71)", []string{"named tomato"}},
+		{"#0ff", "CSS color #00ffff is rgb(0, 255, 255)", []string{"named aqua or cyan"}},
+		{"#123456", "CSS color #123456 is rgb(18, 52, 86)", nil},
+	} {
+		gs := guessCSSColor(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess || !reflect.DeepEqual(gs[0].additional, tc.additional) {
+			t.Errorf("guessCSSColor(%q) = %+v, want %q %q", tc.in, gs, tc.guess, tc.additional)
+		}
+	}
+	for _, s := range []string{"tomatoes", "ff6347", "#ff634", "#gggggg", "#"} {
+		if gs := guessCSSColor(s); gs != nil {
+			t.Errorf("guessCSSColor(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestCSSColorTable(t *testing.T) {
+	if n := len(cssColors); n != 148 {
+		t.Errorf("%d CSS colors, want 148", n)
+	}
+	for name, hex := range cssColors {
+		if len(hex) != 6 || !isHex(hex) {
+			t.Errorf("CSS color %s has bad value %q", name, hex)
+		}
+	}
+}
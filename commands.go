@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A command is a verb like encode that can be given before the arguments
+// to do one particular thing instead of guessing.
+type command struct {
+	args  int // number of arguments after the verb
+	usage string
+	run   func(w io.Writer, args []string) error
+}
+
+var commands = map[string]command{
+	"now":    {0, "now", runNow},
+	"encode": {2, "encode <hex|base64|base64url|base32> <string>", runEncode},
+	"decode": {2, "decode <hex|base64|base32> <string>", runDecode},
+}
+
+// runCommand runs the command given by args[0], if there is one with that
+// name and the right number of arguments.  It returns false if args are
+// to be guessed instead, so that e.g. a lone "encode" is still guessed.
+// Commands print plain text, so with flags that shape the guesses or
+// their output, like -json or -only, args are always guessed, and "now"
+// is the keyword.
+func runCommand(w io.Writer, args []string) (bool, error) {
+	if len(args) == 0 || *jsonOutput || *jsonStream || *tableOutput || *markdown || *stable || *only != "" {
+		return false, nil
+	}
+	cmd, ok := commands[args[0]]
+	if !ok || len(args)-1 != cmd.args {
+		return false, nil
+	}
+	return true, cmd.run(w, args[1:])
+}
+
+// runNow shows the current time in all -timezones and as UNIX timestamps.
+func runNow(w io.Writer, args []string) error {
+	t := now()
+	g := dateGuess(t)
+	g.guess = "Now is " + g.guess
+	g.comment = ""
+	have := map[string]bool{}
+	for _, l := range g.additional {
+		have[l] = true
+	}
+	for _, l := range []string{
+		fmt.Sprintf("UNIX timestamp (milliseconds): %d", t.UnixMilli()),
+		fmt.Sprintf("UNIX timestamp (microseconds): %d", t.UnixMicro()),
+		fmt.Sprintf("UNIX timestamp (nanoseconds): %d", t.UnixNano()),
+	} {
+		// dateGuess may have shown one of them already.
+		if !have[l] {
+			g.additional = append(g.additional, l)
+		}
+	}
+	fmt.Fprint(w, g.String())
+	return nil
+}
+
+func runEncode(w io.Writer, args []string) error {
+	s, err := encodeInput(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, s)
+	return nil
+}
+
+func runDecode(w io.Writer, args []string) error {
+	s, err := decodeInput(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, s)
+	return nil
+}
+
+// encodeInput is the opposite of decodeInput.
+func encodeInput(enc, s string) (string, error) {
+	switch strings.ToLower(enc) {
+	case "hex":
+		return hex.EncodeToString([]byte(s)), nil
+	case "base64":
+		return base64.StdEncoding.EncodeToString([]byte(s)), nil
+	case "base64url":
+		return base64.RawURLEncoding.EncodeToString([]byte(s)), nil
+	case "base32":
+		return base32.StdEncoding.EncodeToString([]byte(s)), nil
+	}
+	return "", fmt.Errorf("unknown encoding %q (want hex, base64, base64url or base32)", enc)
+}
+
+// commandUsage lists the commands for the usage message.
+func commandUsage() []string {
+	var us []string
+	for _, name := range []string{"now", "encode", "decode"} {
+		us = append(us, commands[name].usage)
+	}
+	return us
+}
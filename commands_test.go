@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunCommand(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"encode", "base64", "hello?"}, "aGVsbG8/\n"},
+		{[]string{"encode", "base64url", "hello?"}, "aGVsbG8_\n"},
+		{[]string{"encode", "hex", "hi"}, "6869\n"},
+		{[]string{"encode", "base32", "hi"}, "NBUQ====\n"},
+		{[]string{"decode", "hex", "6869"}, "hi\n"},
+		{[]string{"decode", "base64", "aGVsbG8/"}, "hello?\n"},
+	} {
+		var buf bytes.Buffer
+		ok, err := runCommand(&buf, tc.args)
+		if !ok || err != nil || buf.String() != tc.want {
+			t.Errorf("runCommand(%q) = %v, %v and printed %q, want %q", tc.args, ok, err, buf.String(), tc.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if ok, err := runCommand(&buf, []string{"now"}); !ok || err != nil {
+		t.Errorf("runCommand(now) = %v, %v", ok, err)
+	}
+	if out := buf.String(); !strings.HasPrefix(out, "Now is 2015-09-27 09:28:42.085 +0000 UTC\n") || !strings.Contains(out, "UNIX timestamp (nanoseconds): 1443346122085000000") {
+		t.Errorf("runCommand(now) printed\n%s", out)
+	} else if n := strings.Count(out, "(milliseconds)"); n != 1 {
+		t.Errorf("runCommand(now) printed the milliseconds %d times:\n%s", n, out)
+	}
+
+	if ok, err := runCommand(&buf, []string{"encode", "rot13", "hi"}); !ok || err == nil {
+		t.Errorf("runCommand(encode rot13) = %v, %v, want an error", ok, err)
+	}
+	for _, args := range [][]string{{"encode"}, {"decode", "hex"}, {"now", "please"}, {"1443346122"}, {}} {
+		if ok, _ := runCommand(&buf, args); ok {
+			t.Errorf("runCommand(%q) ran a command", args)
+		}
+	}
+}
+
+func TestRunCommandWithOutputFlags(t *testing.T) {
+	for _, f := range []struct{ name, value string }{
+		{"json", "true"},
+		{"json-stream", "true"},
+		{"table", "true"},
+		{"markdown", "true"},
+		{"stable", "true"},
+		{"only", "keyword"},
+	} {
+		t.Run(f.name, func(t *testing.T) {
+			setFlag(t, f.name, f.value)
+			var buf bytes.Buffer
+			if ok, err := runCommand(&buf, []string{"now"}); ok || err != nil || buf.Len() > 0 {
+				t.Errorf("runCommand(now) with -%s = %v, %v and printed %q, want it guessed", f.name, ok, err, buf.String())
+			}
+		})
+	}
+}
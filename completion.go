@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Flags left out of -help and of the completion scripts.
+var hiddenFlags = map[string]bool{"completion": true}
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage of %s:\n", flag.CommandLine.Name())
+		visible().PrintDefaults()
+	}
+}
+
+// visible returns a copy of the command line flags without the hidden
+// ones.
+func visible() *flag.FlagSet {
+	fs := flag.NewFlagSet(flag.CommandLine.Name(), flag.ContinueOnError)
+	fs.SetOutput(flag.CommandLine.Output())
+	flag.VisitAll(func(f *flag.Flag) {
+		if hiddenFlags[f.Name] {
+			return
+		}
+		fs.Var(f.Value, f.Name, f.Usage)
+		// The value may have been set by now.
+		fs.Lookup(f.Name).DefValue = f.DefValue
+	})
+	return fs
+}
+
+// takesValue is false for boolean flags, which don't need an argument.
+func takesValue(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return !ok || !b.IsBoolFlag()
+}
+
+// option returns how a flag is usually written, -c or --clipboard.
+func option(f *flag.Flag) string {
+	if len(f.Name) == 1 {
+		return "-" + f.Name
+	}
+	return "--" + f.Name
+}
+
+// commandNames returns the names of the commands, sorted.
+func commandNames() []string {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeCompletion prints a script for shell that completes the flags and
+// commands of the program called name.
+func writeCompletion(w io.Writer, shell, name string) error {
+	var flags []*flag.Flag
+	visible().VisitAll(func(f *flag.Flag) { flags = append(flags, f) })
+
+	switch shell {
+	case "bash":
+		var words, valued []string
+		for _, f := range flags {
+			words = append(words, option(f))
+			if takesValue(f) {
+				valued = append(valued, option(f))
+			}
+		}
+		fn := "_" + strings.NewReplacer("-", "_", ".", "_").Replace(name)
+		fmt.Fprintf(w, "%s() {\n", fn)
+		fmt.Fprintf(w, "\tlocal cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]}\n")
+		fmt.Fprintf(w, "\tcase $prev in\n")
+		if len(valued) > 0 {
+			fmt.Fprintf(w, "\t%s) return ;;\n", strings.Join(valued, "|"))
+		}
+		fmt.Fprintf(w, "\tesac\n")
+		fmt.Fprintf(w, "\tif [[ $cur == -* ]]; then\n")
+		fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W '%s' -- \"$cur\"))\n", strings.Join(words, " "))
+		fmt.Fprintf(w, "\telif [[ $COMP_CWORD -eq 1 ]]; then\n")
+		fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W '%s' -- \"$cur\"))\n", strings.Join(commandNames(), " "))
+		fmt.Fprintf(w, "\tfi\n")
+		fmt.Fprintf(w, "}\n")
+		fmt.Fprintf(w, "complete -F %s %s\n", fn, name)
+	case "zsh":
+		quote := strings.NewReplacer("'", `'\''`, "[", `\[`, "]", `\]`, ":", `\:`)
+		fmt.Fprintf(w, "#compdef %s\n\n", name)
+		fmt.Fprintf(w, "_arguments \\\n")
+		for _, f := range flags {
+			if takesValue(f) {
+				fmt.Fprintf(w, "\t'%s=[%s]:%s:' \\\n", option(f), quote.Replace(f.Usage), f.Name)
+			} else {
+				fmt.Fprintf(w, "\t'%s[%s]' \\\n", option(f), quote.Replace(f.Usage))
+			}
+		}
+		fmt.Fprintf(w, "\t'1:command or string:(%s)'\n", strings.Join(commandNames(), " "))
+	case "fish":
+		quote := strings.NewReplacer(`\`, `\\`, "'", `\'`)
+		for _, f := range flags {
+			opt := "-l " + f.Name
+			if len(f.Name) == 1 {
+				opt = "-s " + f.Name
+			}
+			if takesValue(f) {
+				opt += " -r"
+			}
+			fmt.Fprintf(w, "complete -c %s %s -d '%s'\n", name, opt, quote.Replace(f.Usage))
+		}
+		fmt.Fprintf(w, "complete -c %s -n __fish_use_subcommand -a '%s'\n", name, strings.Join(commandNames(), " "))
+	default:
+		return fmt.Errorf("unknown shell %q, must be bash, zsh or fish", shell)
+	}
+	return nil
+}
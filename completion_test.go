@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteCompletion(t *testing.T) {
+	for _, tc := range []struct {
+		shell string
+		want  []string
+	}{
+		{"bash", []string{"complete -F _guess guess", "--json", "|--timezones|", " -c ", "'decode encode now'"}},
+		{"zsh", []string{"#compdef guess", "'--json[Print guesses as a JSON document]'", "'--timezones=[", "'-c[", "(decode encode now)"}},
+		{"fish", []string{"complete -c guess -l json -d 'Print guesses as a JSON document'", "complete -c guess -l timezones -r", "complete -c guess -s c -d", "-a 'decode encode now'"}},
+	} {
+		var buf bytes.Buffer
+		if err := writeCompletion(&buf, tc.shell, "guess"); err != nil {
+			t.Fatalf("writeCompletion(%s) = %v", tc.shell, err)
+		}
+		out := buf.String()
+		for _, w := range tc.want {
+			if !strings.Contains(out, w) {
+				t.Errorf("writeCompletion(%s) printed no %q:\n%s", tc.shell, w, out)
+			}
+		}
+		if strings.Contains(out, "completion") {
+			t.Errorf("writeCompletion(%s) completes the hidden -completion:\n%s", tc.shell, out)
+		}
+	}
+	if err := writeCompletion(&bytes.Buffer{}, "csh", "guess"); err == nil {
+		t.Error("writeCompletion(csh) succeeded")
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layouts of timestamptz values as Postgres prints them, with an offset
+// in hours like +00, or with minutes or seconds for odd zones.
+var pgTimestampLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999-07:00:00",
+}
+
+// guessPGTimestamp recognizes Postgres timestamptz values like
+// 2015-09-26 11:29:43.123456+00.
+func guessPGTimestamp(s string) []Guess {
+	if len(s) < len("2006-01-02 15:04:05+00") || s[10] != ' ' {
+		return nil
+	}
+	for _, layout := range pgTimestampLayouts {
+		d, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		g := dateGuess(d)
+		g.source = "Postgres timestamptz"
+		g.goodness += 150
+		return []Guess{g}
+	}
+	return nil
+}
+
+// The time part of a Postgres interval, like -02:03:04.5.
+var pgIntervalTimeRE = regexp.MustCompile(`^([+-])?(\d+):([0-5]\d):([0-5]\d)(\.\d{1,6})?$`)
+
+// Units of Postgres intervals in the order they're printed, with the
+// lengths EXTRACT(EPOCH FROM ...) assumes for them.
+var pgIntervalUnits = []struct {
+	singular, plural string
+	length           time.Duration
+}{
+	{"year", "years", 8766 * time.Hour},
+	{"mon", "mons", 30 * 24 * time.Hour},
+	{"day", "days", 24 * time.Hour},
+}
+
+// addUnits returns d plus n units, and false where that doesn't fit in a
+// time.Duration, which only reaches about 292 years.
+func addUnits(d time.Duration, n int, unit time.Duration) (time.Duration, bool) {
+	if n > math.MaxInt64/int(unit) || n < math.MinInt64/int(unit) {
+		return 0, false
+	}
+	step := time.Duration(n) * unit
+	if step > 0 && d > math.MaxInt64-step || step < 0 && d < math.MinInt64-step {
+		return 0, false
+	}
+	return d + step, true
+}
+
+// guessPGInterval recognizes Postgres intervals in their default output
+// style, like 1 day 02:03:04 or 1 year 2 mons -3 days.  Bare times are
+// left to guessDuration.
+func guessPGInterval(s string) []Guess {
+	fields := strings.Fields(s)
+	var d time.Duration
+	units := 0
+	ok := true
+	inexact := false
+	next := 0
+	for len(fields) >= 2 && next < len(pgIntervalUnits) {
+		i := next
+		for ; i < len(pgIntervalUnits); i++ {
+			if u := pgIntervalUnits[i]; fields[1] == u.singular || fields[1] == u.plural {
+				break
+			}
+		}
+		if i == len(pgIntervalUnits) {
+			return nil
+		}
+		n, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil
+		}
+		if d, ok = addUnits(d, n, pgIntervalUnits[i].length); !ok {
+			trace("Postgres interval %q is too long for a duration", s)
+			return nil
+		}
+		if i < 2 && n != 0 {
+			inexact = true
+		}
+		units++
+		next = i + 1
+		fields = fields[2:]
+	}
+	if units == 0 || len(fields) > 1 {
+		return nil
+	}
+	if len(fields) == 1 {
+		m := pgIntervalTimeRE.FindStringSubmatch(fields[0])
+		if m == nil {
+			return nil
+		}
+		h, _ := strconv.Atoi(m[2])
+		min, _ := strconv.Atoi(m[3])
+		sec, _ := strconv.Atoi(m[4])
+		micros := 0
+		if m[5] != "" {
+			// Up to six digits after the point, padded to microseconds.
+			micros, _ = strconv.Atoi((m[5][1:] + "00000")[:6])
+		}
+		sign := 1
+		if m[1] == "-" {
+			sign = -1
+		}
+		for _, p := range []struct {
+			n    int
+			unit time.Duration
+		}{
+			{h, time.Hour},
+			{min, time.Minute},
+			{sec, time.Second},
+			{micros, time.Microsecond},
+		} {
+			if d, ok = addUnits(d, sign*p.n, p.unit); !ok {
+				trace("Postgres interval %q is too long for a duration", s)
+				return nil
+			}
+		}
+	}
+
+	additional := []string{fmt.Sprintf("total seconds: %s", strconv.FormatFloat(d.Seconds(), 'f', -1, 64))}
+	if inexact {
+		additional = append(additional, "counting months as 30 days and years as 365.25 days, like Postgres")
+	}
+	return []Guess{{
+		guess:      "Duration " + d.String(),
+		comment:    s + " as Postgres interval",
+		additional: additional,
+		source:     "Postgres interval",
+		goodness:   150,
+	}}
+}
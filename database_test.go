@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGuessPGTimestamp(t *testing.T) {
+	for _, tc := range []struct{ in, want, source string }{
+		{"2015-09-26 11:29:43.123456+00", "2015-09-26 11:29:43.123456 +0000 UTC", "Postgres timestamptz"},
+		{"2015-09-27 11:29:43+05:30", "2015-09-27 11:29:43 +0530 +0530", "Postgres timestamptz"},
+		{"1890-01-01 00:00:00+00:53:28", "1890-01-01 00:00:00 +0053 +0053", "Postgres timestamptz"},
+	} {
+		gs := guessPGTimestamp(tc.in)
+		if len(gs) != 1 || !strings.HasPrefix(gs[0].guess, tc.want) || gs[0].source != tc.source {
+			t.Errorf("guessPGTimestamp(%q) = %+v, want %q", tc.in, gs, tc.want)
+		}
+	}
+	for _, s := range []string{"2015-09-26 11:29:43", "2015-09-26T11:29:43+00", "2015-09-26 11:29:43 +0000"} {
+		if gs := guessPGTimestamp(s); gs != nil {
+			t.Errorf("guessPGTimestamp(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessPGInterval(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess  string
+		additional []string
+	}{
+		{"1 day 02:03:04", "Duration 26h3m4s", []string{"total seconds: 93784"}},
+		{"3 days", "Duration 72h0m0s", []string{"total seconds: 259200"}},
+		{"-1 days +02:03:04.5", "Duration -21h56m55.5s", []string{"total seconds: -79015.5"}},
+		{"1 year 2 mons -3 days", "Duration 10134h0m0s", []string{"total seconds: 36482400", "counting months as 30 days and years as 365.25 days, like Postgres"}},
+		{"0 years 0 mons 1 day", "Duration 24h0m0s", []string{"total seconds: 86400"}},
+		{"1 day 00:00:00.000249", "Duration 24h0m0.000249s", []string{"total seconds: 86400.000249"}},
+		{"1 day 00:00:00.000251", "Duration 24h0m0.000251s", []string{"total seconds: 86400.000251"}},
+		{"1 day 00:00:01.5", "Duration 24h0m1.5s", []string{"total seconds: 86401.5"}},
+	} {
+		gs := guessPGInterval(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess || !reflect.DeepEqual(gs[0].additional, tc.additional) || gs[0].source != "Postgres interval" {
+			t.Errorf("guessPGInterval(%q) = %+v, want %q %q", tc.in, gs, tc.guess, tc.additional)
+		}
+	}
+	for _, s := range []string{"02:03:04", "1 day 2 years", "1 week", "x days", "1 day 02:03", "1 day 02:03:04 ago", "day", "1000 years", "300 years 1 day", "-300 years", "292 years 1000000:00:00", "1 day 99999999999999999:00:00"} {
+		if gs := guessPGInterval(s); gs != nil {
+			t.Errorf("guessPGInterval(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"guess/bytesize"
+)
+
+// diffValues compares a and b if both are durations, byte sizes with units
+// or dates, returning nil if they aren't of the same kind.
+func diffValues(a, b string) []Guess {
+	if da, err := time.ParseDuration(a); err == nil {
+		if db, err := time.ParseDuration(b); err == nil {
+			g := Guess{
+				guess:    fmt.Sprintf("Difference: %v", db-da),
+				source:   "difference of two durations",
+				goodness: 200,
+			}
+			if da != 0 {
+				g.additional = []string{fmt.Sprintf("ratio: %s", formatRatio(float64(db)/float64(da)))}
+			}
+			return []Guess{g}
+		}
+		return nil
+	}
+
+	if isBytesWithUnit(a) && isBytesWithUnit(b) {
+		na, _ := bytesize.ParseBytes(a)
+		nb, _ := bytesize.ParseBytes(b)
+		g := Guess{
+			guess:      fmt.Sprintf("Difference: %d bytes", nb-na),
+			additional: []string{fmt.Sprintf("that is %s or %s", bytesize.FormatBytes(nb-na), bytesize.FormatBytesDecimal(nb-na))},
+			source:     "difference of two byte sizes",
+			goodness:   200,
+		}
+		if na != 0 {
+			g.additional = append(g.additional, fmt.Sprintf("ratio: %s", formatRatio(float64(nb)/float64(na))))
+		}
+		return []Guess{g}
+	}
+
+	ta, errA := parseAnchor(a)
+	tb, errB := parseAnchor(b)
+	if errA != nil || errB != nil {
+		return nil
+	}
+	_, desc := deltaFrom(ta, tb)
+	return []Guess{{
+		guess:    fmt.Sprintf("Difference: %v", tb.Sub(ta)),
+		comment:  fmt.Sprintf("%s is %s relative to %s", b, desc, a),
+		source:   "difference of two dates",
+		goodness: 200,
+	}}
+}
+
+// isBytesWithUnit returns whether s is a byte size with an explicit unit,
+// as plain numbers could just as well be timestamps.
+func isBytesWithUnit(s string) bool {
+	if _, err := parseInt(s); err == nil {
+		return false
+	}
+	_, err := bytesize.ParseBytes(s)
+	return err == nil
+}
+
+func formatRatio(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// printDiff prints how b differs from a or, if they can't be compared,
+// the guesses for each of them.  It returns false if there was nothing to
+// print.
+func printDiff(w io.Writer, a, b string) bool {
+	if gs := diffValues(a, b); gs != nil {
+		return printGuesses(w, gs)
+	}
+	fmt.Fprintf(w, "Cannot compare %q and %q, guessing each on its own.\n", a, b)
+	ok := false
+	for _, s := range []string{a, b} {
+		fmt.Fprintf(w, "\n%s:\n", cHighlight(s))
+		gs := guess(s)
+		if *sortGuesses {
+			sort.Sort(ByGoodness(gs))
+		}
+		if printGuesses(w, gs) {
+			ok = true
+		}
+	}
+	return ok
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffValues(t *testing.T) {
+	for _, tc := range []struct {
+		a, b, guess, comment string
+		additional           []string
+	}{
+		{"1h30m", "45m", "Difference: -45m0s", "", []string{"ratio: 0.5"}},
+		{"1GiB", "1.5GiB", "Difference: 536870912 bytes", "", []string{"that is 512 MiB or 536.870912 MB", "ratio: 1.5"}},
+		{"2015-09-26T11:29:43-07:00", "2015-09-27T09:28:42Z", "Difference: 14h58m59s", "2015-09-27T09:28:42Z is within the day, 14 hours 58 minutes 59 seconds ahead relative to 2015-09-26T11:29:43-07:00", nil},
+		{"2015-09-27", "2015-09-20", "Difference: -168h0m0s", "2015-09-20 is 7 days ago relative to 2015-09-27", nil},
+	} {
+		gs := diffValues(tc.a, tc.b)
+		if len(gs) != 1 || gs[0].guess != tc.guess || gs[0].comment != tc.comment {
+			t.Errorf("diffValues(%q, %q) = %+v, want %q (%s)", tc.a, tc.b, gs, tc.guess, tc.comment)
+			continue
+		}
+		if !reflect.DeepEqual(gs[0].additional, tc.additional) {
+			t.Errorf("diffValues(%q, %q) additional = %q, want %q", tc.a, tc.b, gs[0].additional, tc.additional)
+		}
+	}
+	for _, tc := range [][2]string{{"1h", "1GiB"}, {"1GiB", "2015-09-27"}, {"xyzzy", "2015-09-27"}} {
+		if gs := diffValues(tc[0], tc[1]); gs != nil {
+			t.Errorf("diffValues(%q, %q) = %+v, want nil", tc[0], tc[1], gs)
+		}
+	}
+}
+
+func TestPrintDiffMismatch(t *testing.T) {
+	var buf bytes.Buffer
+	if !printDiff(&buf, "1h", "127.0.0.1") {
+		t.Fatal("printDiff() = false")
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "Cannot compare \"1h\" and \"127.0.0.1\"") || !strings.Contains(out, "IP address 127.0.0.1") {
+		t.Errorf("printDiff() printed\n%s", out)
+	}
+}
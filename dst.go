@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// How close to a daylight saving time transition a date has to be for it
+// to be pointed out.
+const dstWindow = 24 * time.Hour
+
+// dstLines warns about daylight saving time transitions in any of TZs
+// within a day of t, when local times are skipped or happen twice.
+func dstLines(t time.Time) []string {
+	var descs []string
+	zones := map[string][]string{}
+	for _, loc := range TZs {
+		start, end := t.In(loc).ZoneBounds()
+		for _, tr := range []time.Time{start, end} {
+			if tr.IsZero() || tr.Sub(t) > dstWindow || t.Sub(tr) > dstWindow {
+				continue
+			}
+			desc := describeTransition(tr, loc, t)
+			if zones[desc] == nil {
+				descs = append(descs, desc)
+			}
+			zones[desc] = append(zones[desc], loc.String())
+		}
+	}
+	var lines []string
+	for _, desc := range descs {
+		lines = append(lines, fmt.Sprintf("Clocks in %s %s", strings.Join(zones[desc], ", "), desc))
+	}
+	return lines
+}
+
+// zoneNote tells, with -explain-timezone, whether loc is on standard or
+// daylight saving time at t.  Both that and the abbreviation and offset
+// depend on the date, so where the latter differ from now, which they do
+// for half of the year, those of now are given too, except with -stable.
+func zoneNote(t time.Time, loc *time.Location) string {
+	if !*explainTZ {
+		return ""
+	}
+	describe := func(t time.Time) string {
+		abbrev, _ := t.Zone()
+		return fmt.Sprintf("%s, UTC%s", abbrev, t.Format("-07:00"))
+	}
+	at := t.In(loc)
+	note := "standard time"
+	if at.IsDST() {
+		note = "daylight saving time"
+	}
+	if cur := now().In(loc); !*stable && describe(cur) != describe(at) {
+		note += fmt.Sprintf("; now %s", describe(cur))
+	}
+	return note
+}
+
+// describeTransition describes the transition at tr in loc relative to t,
+// like "fall back 1h at 2015-10-25 03:00, 2h after this: 02:00 to 02:59
+// happens twice".
+func describeTransition(tr time.Time, loc *time.Location, t time.Time) string {
+	_, before := tr.Add(-time.Second).In(loc).Zone()
+	_, after := tr.In(loc).Zone()
+	shift := time.Duration(after-before) * time.Second
+	// The transition in wall clock time before it happens.
+	wall := tr.In(time.FixedZone("", before))
+
+	when := "at this time"
+	switch d := tr.Sub(t).Round(time.Minute); {
+	case d > 0:
+		when = shortDuration(d) + " after this"
+	case d < 0:
+		when = shortDuration(-d) + " before this"
+	}
+	if shift > 0 {
+		return fmt.Sprintf("spring forward %s at %s, %s: %s to %s does not exist",
+			shortDuration(shift), wall.Format("2006-01-02 15:04"), when,
+			wall.Format("15:04"), wall.Add(shift-time.Minute).Format("15:04"))
+	}
+	return fmt.Sprintf("fall back %s at %s, %s: %s to %s happens twice",
+		shortDuration(-shift), wall.Format("2006-01-02 15:04"), when,
+		wall.Add(shift).Format("15:04"), wall.Add(-time.Minute).Format("15:04"))
+}
+
+// shortDuration formats d like 2h30m or 1h, dropping zero minutes and
+// seconds.
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// wallTimeInstants returns the instants at which clocks in loc show the
+// date and time of day of wall: none if daylight saving time skips it, two
+// if it happens twice when clocks are set back, otherwise one.
+// time.ParseInLocation always returns one of them, without telling.
+func wallTimeInstants(wall time.Time, loc *time.Location) []time.Time {
+	y, mo, d := wall.Date()
+	h, mi, s := wall.Clock()
+	w := time.Date(y, mo, d, h, mi, s, wall.Nanosecond(), time.UTC)
+	var ts []time.Time
+	for _, probe := range []time.Time{w.Add(-dstWindow), w, w.Add(dstWindow)} {
+		_, off := probe.In(loc).Zone()
+		t := w.Add(-time.Duration(off) * time.Second).In(loc)
+		ty, tmo, td := t.Date()
+		th, tmi, ts2 := t.Clock()
+		if ty != y || tmo != mo || td != d || th != h || tmi != mi || ts2 != s {
+			continue
+		}
+		if len(ts) == 0 || !ts[len(ts)-1].Equal(t) {
+			ts = append(ts, t)
+		}
+	}
+	return ts
+}
+
+// wallTimeNote points out if the local time of wall doesn't exist in loc or
+// is ambiguous there, or returns "".
+func wallTimeNote(wall time.Time, loc *time.Location) string {
+	switch ts := wallTimeInstants(wall, loc); len(ts) {
+	case 0:
+		return fmt.Sprintf("%s does not exist there, clocks skip it", wall.Format("15:04"))
+	case 2:
+		return fmt.Sprintf("ambiguous, %s happens twice there: at %s and at %s",
+			wall.Format("15:04"), formatTime(ts[0].Local()), formatTime(ts[1].Local()))
+	}
+	return ""
+}
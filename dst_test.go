@@ -0,0 +1,145 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDSTLines(t *testing.T) {
+	for _, tc := range []struct {
+		t    time.Time
+		want []string
+	}{
+		{
+			time.Date(2015, 10, 24, 23, 0, 0, 0, time.UTC),
+			[]string{"Clocks in Europe/Berlin fall back 1h at 2015-10-25 03:00, 2h after this: 02:00 to 02:59 happens twice"},
+		},
+		{
+			time.Date(2015, 3, 8, 9, 30, 0, 0, time.UTC),
+			[]string{
+				"Clocks in America/Los_Angeles spring forward 1h at 2015-03-08 02:00, 30m after this: 02:00 to 02:59 does not exist",
+				"Clocks in America/New_York spring forward 1h at 2015-03-08 02:00, 2h30m before this: 02:00 to 02:59 does not exist",
+			},
+		},
+		{
+			time.Date(2015, 10, 4, 1, 0, 0, 0, time.UTC),
+			[]string{"Clocks in Australia/Sydney spring forward 1h at 2015-10-04 02:00, 9h before this: 02:00 to 02:59 does not exist"},
+		},
+		{testNow, nil},
+	} {
+		if got := dstLines(tc.t); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("dstLines(%v) = %q, want %q", tc.t, got, tc.want)
+		}
+	}
+
+	old := TZs
+	defer func() { TZs = old }()
+	var err error
+	TZs, err = loadTimezones("Europe/Berlin,Europe/Paris,UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Clocks in Europe/Berlin, Europe/Paris fall back 1h at 2015-10-25 03:00, at this time: 02:00 to 02:59 happens twice"}
+	if got := dstLines(time.Date(2015, 10, 25, 1, 0, 0, 0, time.UTC)); !reflect.DeepEqual(got, want) {
+		t.Errorf("dstLines() = %q, want %q", got, want)
+	}
+}
+
+func TestZoneNote(t *testing.T) {
+	old := TZs
+	defer func() { TZs = old }()
+	var err error
+	TZs, err = loadTimezones("America/Los_Angeles,UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	winter := time.Date(2015, 1, 15, 20, 0, 0, 0, time.UTC)
+	if got := zoneNote(winter, TZs[0]); got != "" {
+		t.Errorf("zoneNote() without -explain-timezone = %q, want none", got)
+	}
+	setFlag(t, "explain-timezone", "true")
+	for _, tc := range []struct {
+		t    time.Time
+		want []string
+	}{
+		// Winter, when it is summer now.
+		{winter, []string{
+			"2015-01-15 12:00:00 -0800 PST (America/Los_Angeles: standard time; now PDT, UTC-07:00)",
+			"2015-01-15 20:00:00 +0000 UTC (UTC: standard time)",
+		}},
+		// Summer, like now.
+		{time.Date(2015, 7, 15, 20, 0, 0, 0, time.UTC), []string{
+			"2015-07-15 13:00:00 -0700 PDT (America/Los_Angeles: daylight saving time)",
+			"2015-07-15 20:00:00 +0000 UTC (UTC: standard time)",
+		}},
+	} {
+		if got := differentTZs(tc.t); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("differentTZs(%v) = %q, want %q", tc.t, got, tc.want)
+		}
+	}
+	setFlag(t, "stable", "true")
+	if got, want := zoneNote(winter, TZs[0]), "standard time"; got != want {
+		t.Errorf("zoneNote() with -stable = %q, want %q", got, want)
+	}
+}
+
+func TestShortDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		time.Hour:                    "1h",
+		90 * time.Minute:             "1h30m",
+		30 * time.Minute:             "30m",
+		25*time.Hour + 5*time.Second: "25h0m5s",
+	} {
+		if got := shortDuration(d); got != want {
+			t.Errorf("shortDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestWallTimeInstants(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		wall time.Time
+		want []string
+	}{
+		{time.Date(2015, 3, 8, 2, 30, 0, 0, time.UTC), nil},
+		{time.Date(2015, 3, 8, 3, 30, 0, 0, time.UTC), []string{"2015-03-08 03:30:00 -0400 EDT"}},
+		{time.Date(2015, 11, 1, 1, 30, 0, 0, time.UTC), []string{"2015-11-01 01:30:00 -0400 EDT", "2015-11-01 01:30:00 -0500 EST"}},
+		{time.Date(2015, 9, 27, 9, 28, 42, 0, time.UTC), []string{"2015-09-27 09:28:42 -0400 EDT"}},
+	} {
+		var got []string
+		for _, i := range wallTimeInstants(tc.wall, ny) {
+			got = append(got, i.String())
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("wallTimeInstants(%v) = %q, want %q", tc.wall, got, tc.want)
+		}
+	}
+}
+
+func TestGuessBadDateDST(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"2015-03-08 02:30:00", "From EST (America/New_York): 2015-03-08 06:30:00 +0000 UTC; 02:30 does not exist there, clocks skip it"},
+		{"2015-11-01 01:30:00", "From EDT (America/New_York): 2015-11-01 05:30:00 +0000 UTC; ambiguous, 01:30 happens twice there: at 2015-11-01 05:30:00 +0000 UTC and at 2015-11-01 06:30:00 +0000 UTC"},
+		{"2015-10-25 02:30:00", "From CET (Europe/Berlin): 2015-10-25 01:30:00 +0000 UTC; ambiguous, 02:30 happens twice there: at 2015-10-25 00:30:00 +0000 UTC and at 2015-10-25 01:30:00 +0000 UTC"},
+	} {
+		gs := guessBuiltinDate(tc.in)
+		if len(gs) != 1 {
+			t.Fatalf("guessBuiltinDate(%q) = %+v", tc.in, gs)
+		}
+		found := false
+		for _, l := range gs[0].additional {
+			found = found || strings.HasPrefix(l, tc.want)
+		}
+		if !found {
+			t.Errorf("guessBuiltinDate(%q) additional = %q, want a line %q", tc.in, gs[0].additional, tc.want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Durations like 1:23:45, 123:45:00.5 or 83:45, as shown by stopwatches and
+// media players.
+var colonDurationRE = regexp.MustCompile(`^(\d+):([0-5]\d)(?::([0-5]\d))?(\.\d{1,9})?$`)
+
+// guessDuration interprets colon-separated numbers as hours, minutes and
+// seconds, or just minutes and seconds if there are two of them.  These
+// look like times of day unless the first field is 24 or more, so they
+// usually rank below that interpretation.  Durations with units like 1h30m
+// or 5m, as Go and many config files write them, are understood too.
+func guessDuration(s string) []Guess {
+	m := colonDurationRE.FindStringSubmatch(s)
+	if m == nil {
+		return guessUnitDuration(s)
+	}
+	first, err := strconv.Atoi(m[1])
+	if err != nil {
+		return nil
+	}
+	second, _ := strconv.Atoi(m[2])
+	var d time.Duration
+	var layout string
+	goodness := 80
+	if m[3] == "" {
+		d = time.Duration(first)*time.Minute + time.Duration(second)*time.Second
+		layout = "minutes:seconds"
+		goodness = 20
+	} else {
+		third, _ := strconv.Atoi(m[3])
+		d = time.Duration(first)*time.Hour + time.Duration(second)*time.Minute + time.Duration(third)*time.Second
+		layout = "hours:minutes:seconds"
+	}
+	if m[4] != "" {
+		frac, _ := strconv.ParseFloat("0"+m[4], 64)
+		d += time.Duration(frac * float64(time.Second))
+	}
+	if first >= 24 {
+		trace("%q cannot be a time of day", s)
+		goodness = 150
+	}
+	return []Guess{{
+		guess:      "Duration " + d.String(),
+		comment:    fmt.Sprintf("%s as %s", s, layout),
+		additional: []string{fmt.Sprintf("total seconds: %s", strconv.FormatFloat(d.Seconds(), 'f', -1, 64))},
+		source:     "duration",
+		goodness:   goodness,
+	}}
+}
+
+// guessUnitDuration interprets durations in the syntax of
+// time.ParseDuration, like 1h30m, 250ms or 5m.  A sign is left to
+// guessKeyword, which reads it as an offset from now.
+func guessUnitDuration(s string) []Guess {
+	if !strings.ContainsAny(s[:min(len(s), 1)], "0123456789.") || !strings.ContainsAny(s, "hmnsuµ") {
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return nil
+	}
+	return []Guess{{
+		guess:      "Duration " + d.String(),
+		additional: []string{fmt.Sprintf("total seconds: %s", strconv.FormatFloat(d.Seconds(), 'f', -1, 64))},
+		source:     "duration",
+		goodness:   80,
+	}}
+}
+
+// SMPTE timecodes HH:MM:SS:FF, with a ; before the frames (or everywhere)
+// for drop-frame timecode.
+var timecodeRE = regexp.MustCompile(`^(\d{2})([:;.])([0-5]\d)([:;.])([0-5]\d)([:;.])(\d{2})$`)
+
+// Frame rates timecodes are shown for unless -fps picks one.
+var (
+	commonFrameRates    = []float64{24, 25, 30000.0 / 1001, 30}
+	dropFrameFrameRates = []float64{30000.0 / 1001}
+)
+
+// timecodeFrames returns the number of frames since 00:00:00:00 at the
+// given rate, or false if there is no such timecode.  Drop-frame timecode
+// skips the first frame numbers of each minute except every tenth to stay
+// in sync with the wall clock at 29.97 and 59.94 fps.
+func timecodeFrames(h, m, s, f int, rate float64, drop bool) (int, bool) {
+	nominal := int(math.Round(rate))
+	if f >= nominal {
+		return 0, false
+	}
+	frames := ((h*60+m)*60+s)*nominal + f
+	if !drop {
+		return frames, true
+	}
+	if nominal != 30 && nominal != 60 || math.Round(rate) == rate {
+		return 0, false
+	}
+	dropped := nominal / 15
+	if s == 0 && m%10 != 0 && f < dropped {
+		return 0, false
+	}
+	minutes := h*60 + m
+	return frames - dropped*(minutes-minutes/10), true
+}
+
+// formatFrameRate renders 29.97002997 as 29.97.
+func formatFrameRate(rate float64) string {
+	return strconv.FormatFloat(math.Round(rate*100)/100, 'f', -1, 64)
+}
+
+// guessTimecode recognizes SMPTE timecodes and converts them to frame
+// counts and durations at -fps or, by default, a few common frame rates.
+func guessTimecode(s string) []Guess {
+	m := timecodeRE.FindStringSubmatch(s)
+	if m == nil {
+		return nil
+	}
+	drop := m[6] == ";"
+	if !drop && (m[2] != m[4] || m[4] != m[6]) {
+		return nil
+	}
+	var f [4]int
+	for i, j := range []int{1, 3, 5, 7} {
+		f[i], _ = strconv.Atoi(m[j])
+	}
+	rates := commonFrameRates
+	if drop {
+		rates = dropFrameFrameRates
+	}
+	if *fps != 0 {
+		rates = []float64{*fps}
+		// 29.97 and 59.94 are the usual shorthands for 30000/1001 and 60000/1001.
+		if r := math.Round(*fps); r != *fps && math.Abs(*fps-r*1000/1001) < 0.01 {
+			rates = []float64{r * 1000 / 1001}
+		}
+	}
+	var lines []string
+	for _, rate := range rates {
+		frames, ok := timecodeFrames(f[0], f[1], f[2], f[3], rate, drop)
+		if !ok {
+			continue
+		}
+		d := time.Duration(float64(frames) / rate * float64(time.Second)).Round(time.Millisecond)
+		lines = append(lines, fmt.Sprintf("at %s fps: frame %d, %s", formatFrameRate(rate), frames, d))
+	}
+	if lines == nil {
+		trace("%q is not a valid timecode at any of %v fps", s, rates)
+		return nil
+	}
+	kind := "non-drop-frame"
+	if drop {
+		kind = "drop-frame"
+	}
+	return []Guess{{
+		guess:      "SMPTE timecode " + s,
+		comment:    kind,
+		additional: lines,
+		source:     "SMPTE timecode",
+		goodness:   150,
+	}}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGuessDuration(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess, total string
+		goodness         int
+	}{
+		{"01:23:45", "Duration 1h23m45s", "total seconds: 5025", 80},
+		{"123:45:00", "Duration 123h45m0s", "total seconds: 445500", 150},
+		{"0:00:01.5", "Duration 1.5s", "total seconds: 1.5", 80},
+		{"14:30", "Duration 14m30s", "total seconds: 870", 20},
+		{"90:00", "Duration 1h30m0s", "total seconds: 5400", 150},
+		{"1h30m", "Duration 1h30m0s", "total seconds: 5400", 80},
+		{"5m", "Duration 5m0s", "total seconds: 300", 80},
+		{".5s", "Duration 500ms", "total seconds: 0.5", 80},
+	} {
+		gs := guessDuration(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess || gs[0].additional[0] != tc.total || gs[0].goodness != tc.goodness {
+			t.Errorf("guessDuration(%q) = %+v, want %q, %q, goodness %d", tc.in, gs, tc.guess, tc.total, tc.goodness)
+		}
+	}
+	for _, s := range []string{"1:60:00", "1:2:3", "12", ":30", "1:23:45:12", "01:23:45Z", "-5m", "+1h", "0", "5x", "m"} {
+		if gs := guessDuration(s); gs != nil {
+			t.Errorf("guessDuration(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessTimecode(t *testing.T) {
+	for _, tc := range []struct {
+		in, fps string
+		lines   []string
+	}{
+		{"01:00:00:00", "0", []string{
+			"at 24 fps: frame 86400, 1h0m0s",
+			"at 25 fps: frame 90000, 1h0m0s",
+			"at 29.97 fps: frame 108000, 1h0m3.6s",
+			"at 30 fps: frame 108000, 1h0m0s",
+		}},
+		{"00:00:01:24", "0", []string{
+			"at 25 fps: frame 49, 1.96s",
+			"at 29.97 fps: frame 54, 1.802s",
+			"at 30 fps: frame 54, 1.8s",
+		}},
+		{"01:00:00;00", "0", []string{"at 29.97 fps: frame 107892, 59m59.996s"}},
+		{"00:01:00;02", "0", []string{"at 29.97 fps: frame 1800, 1m0.06s"}},
+		{"00:00:10:00", "25", []string{"at 25 fps: frame 250, 10s"}},
+		{"00:10:00;00", "59.94", []string{"at 59.94 fps: frame 35964, 9m59.999s"}},
+	} {
+		setFlag(t, "fps", tc.fps)
+		gs := guessTimecode(tc.in)
+		if len(gs) != 1 || !reflect.DeepEqual(gs[0].additional, tc.lines) {
+			t.Errorf("guessTimecode(%q) with -fps %s = %+v, want %q", tc.in, tc.fps, gs, tc.lines)
+		}
+	}
+	setFlag(t, "fps", "0")
+	for _, s := range []string{"00:01:00;00", "00:00:00:30", "01:23:45", "01:00:00.00", "01:00;00:00", "00:00:00;00x"} {
+		if gs := guessTimecode(s); gs != nil {
+			t.Errorf("guessTimecode(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
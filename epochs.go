@@ -0,0 +1,364 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// yearGoodness scores a date that was decoded from an ambiguous number by
+// how far it is from now: numbers that happen to decode to a date close to
+// today are more likely meant as one.
+func yearGoodness(t time.Time) int {
+	years := math.Abs(t.Sub(now()).Hours()) / 24 / 365
+	switch {
+	case years < 1:
+		return 30
+	case years < 10:
+		return 10
+	case years < 50:
+		return 0
+	}
+	return -10
+}
+
+// dayGuess is like dateGuess, but for calendar dates without a time of day,
+// for which other time zones make no sense.
+func dayGuess(t time.Time) Guess {
+	d, dstr := deltaNow(t)
+	var cal []string
+	if d < 365*24*time.Hour || *alwaysCalendar {
+		cal = calendar(t)
+	}
+	lines := append(sunLines(t), weekLines(t)...)
+	lines = append(lines, moonLines(t)...)
+	additional := sideBySide(lines, cal)
+	return Guess{
+		guess:      names.days[t.Weekday()] + ", " + t.Format("2006-01-02"),
+		comment:    dstr,
+		additional: additional,
+		goodness:   yearGoodness(t),
+	}
+}
+
+// guessEpochDay interprets small integers as the number of days since
+// 1970-01-01, which is how e.g. PostgreSQL stores dates internally.
+func guessEpochDay(s string) []Guess {
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 || n >= 50000 {
+		return nil
+	}
+	g := dayGuess(time.Unix(0, 0).UTC().AddDate(0, 0, n))
+	g.guess = fmt.Sprintf("Epoch day %d is %s", n, g.guess)
+	g.source = "days since 1970-01-01"
+	// Small numbers are far more often something else.
+	g.goodness -= 10
+	return []Guess{g}
+}
+
+// DOS and the FAT file system store dates and times as 16-bit values:
+//
+//	date: bits 15-9 year since 1980, bits 8-5 month, bits 4-0 day
+//	time: bits 15-11 hours, bits 10-5 minutes, bits 4-0 seconds/2
+//
+// Where both are stored together as a 32-bit value, the date is in the high
+// word.  The time is local time of whatever machine wrote it.
+
+func dosDate(v uint16) (year int, month time.Month, day int, ok bool) {
+	year, month, day = 1980+int(v>>9), time.Month(v>>5&0xf), int(v&0x1f)
+	ok = month >= 1 && month <= 12 && day >= 1 && day <= 31
+	return
+}
+
+func dosTime(v uint16) (hour, min, sec int, ok bool) {
+	hour, min, sec = int(v>>11), int(v>>5&0x3f), 2*int(v&0x1f)
+	ok = hour < 24 && min < 60 && sec < 60
+	return
+}
+
+func guessDOSDate(s string) []Guess {
+	digits, base := s, 10
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		digits, base = s[2:], 16
+	}
+	n, err := strconv.ParseUint(digits, base, 32)
+	if err != nil || n == 0 {
+		return nil
+	}
+	var gs []Guess
+	if n > 0xffff {
+		y, mo, d, okd := dosDate(uint16(n >> 16))
+		h, mi, sec, okt := dosTime(uint16(n))
+		if !okd || !okt {
+			return nil
+		}
+		t := time.Date(y, mo, d, h, mi, sec, 0, time.Local)
+		if t.Day() != d {
+			return nil // e.g. February 30th
+		}
+		g := dateGuess(t)
+		g.guess = fmt.Sprintf("DOS timestamp %#08x is %s", n, g.guess)
+		g.source = "DOS date and time"
+		g.goodness = yearGoodness(t) - 10
+		return []Guess{g}
+	}
+	if y, mo, d, ok := dosDate(uint16(n)); ok {
+		t := time.Date(y, mo, d, 0, 0, 0, 0, time.UTC)
+		if t.Day() == d {
+			g := dayGuess(t)
+			g.guess = fmt.Sprintf("DOS date %#04x is %s", n, g.guess)
+			g.source = "DOS date"
+			g.goodness = yearGoodness(t) - 20
+			gs = append(gs, g)
+		}
+	}
+	if h, mi, sec, ok := dosTime(uint16(n)); ok {
+		gs = append(gs, Guess{
+			guess:    fmt.Sprintf("DOS time %#04x is %02d:%02d:%02d", n, h, mi, sec),
+			source:   "DOS time",
+			goodness: -10,
+		})
+	}
+	return gs
+}
+
+// Julian dates count days since noon UTC on November 24, 4714 BC in the
+// proleptic Gregorian calendar, so that the UNIX epoch is JD 2440587.5.
+// Modified Julian dates are JD - 2400000.5 and start at midnight, so that the
+// UNIX epoch is MJD 40587.  The Julian Day Number (JDN) is the integer part
+// of the JD, and denotes the day starting at its noon.
+const (
+	jdUnixEpoch  = 2440587.5
+	mjdUnixEpoch = 40587
+	mjdOffset    = 2400000.5
+)
+
+func guessJulianDay(s string) []Guess {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	var jd float64
+	var kind string
+	switch {
+	case f >= 2415020.5 && f < 2488070.5: // 1900 to 2100
+		jd, kind = f, "Julian day"
+	case f >= 15020 && f < 88070:
+		jd, kind = f+mjdOffset, "Modified Julian day"
+	default:
+		return nil
+	}
+	secs := (jd - jdUnixEpoch) * 86400
+	t := time.Unix(0, 0).Add(time.Duration(math.Round(secs)) * time.Second).UTC()
+	g := dateGuess(t)
+	g.guess = fmt.Sprintf("%s %s is %s", kind, s, g.guess)
+	g.additional = append([]string{
+		fmt.Sprintf("JD %s, JDN %d", strconv.FormatFloat(jd, 'f', -1, 64), int64(math.Floor(jd))),
+		fmt.Sprintf("MJD %s", strconv.FormatFloat(jd-mjdOffset, 'f', -1, 64)),
+	}, g.additional...)
+	g.source = strings.ToLower(kind)
+	g.goodness = yearGoodness(t)
+	if kind != "Julian day" {
+		// The MJD range overlaps with lots of other small numbers.
+		g.goodness -= 10
+	}
+	return []Guess{g}
+}
+
+// Spreadsheets store dates as days since an epoch, with the time of day as
+// the fractional part.  In the 1900 date system, day 1 is 1900-01-01, but
+// day 60 is the nonexistent 1900-02-29, a bug inherited from Lotus 1-2-3 for
+// compatibility.  From day 61 onwards, this makes it days since 1899-12-30.
+// The 1904 date system, used by old Mac versions of Excel, simply counts
+// days since 1904-01-01.
+
+func excelDate(serial float64, epoch1904 bool) (time.Time, bool) {
+	days := math.Floor(serial)
+	var base time.Time
+	switch {
+	case epoch1904:
+		base = time.Date(1904, 1, 1, 0, 0, 0, 0, time.Local)
+	case days == 60:
+		return time.Time{}, false
+	case days < 60:
+		base = time.Date(1899, 12, 31, 0, 0, 0, 0, time.Local)
+	default:
+		base = time.Date(1899, 12, 30, 0, 0, 0, 0, time.Local)
+	}
+	t := base.AddDate(0, 0, int(days))
+	frac := time.Duration(math.Round((serial - days) * 86400))
+	return t.Add(frac * time.Second), true
+}
+
+func guessExcelDate(s string) []Guess {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 1 || f >= 73051 { // up to 2100-01-01
+		return nil
+	}
+	var gs []Guess
+	for _, sys := range []struct {
+		epoch1904 bool
+		name      string
+	}{
+		{false, "1900"},
+		{true, "1904"},
+	} {
+		t, ok := excelDate(f, sys.epoch1904)
+		if !ok {
+			continue
+		}
+		var g Guess
+		if f == math.Floor(f) {
+			g = dayGuess(t)
+		} else {
+			g = dateGuess(t)
+		}
+		g.guess = fmt.Sprintf("Spreadsheet date %s is %s", s, g.guess)
+		g.source = "spreadsheet serial date (" + sys.name + " date system)"
+		g.goodness = yearGoodness(t)
+		if sys.epoch1904 {
+			g.goodness -= 10
+		}
+		gs = append(gs, g)
+	}
+	return gs
+}
+
+// Epochs of Snowflake IDs in milliseconds since 1970, selectable with
+// -snowflake-epoch.
+var snowflakeEpochs = []struct {
+	name             string
+	ms               int64
+	worker, sequence string
+}{
+	{"twitter", 1288834974657, "datacenter %d, worker %d", "sequence %d"},
+	{"discord", 1420070400000, "worker %d, process %d", "increment %d"},
+}
+
+// guessSnowflake decodes Snowflake IDs, whose top 42 bits are milliseconds
+// since an epoch, followed by 10 bits identifying the machine and a 12 bit
+// sequence number.  Any large integer decodes to something, so only IDs
+// from between the epoch and now are considered plausible.  Smaller numbers
+// than 2**41 would be from within 10 minutes of the epoch, and are much
+// more likely to be millisecond timestamps.
+func guessSnowflake(s string) []Guess {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 1<<41 {
+		return nil
+	}
+	var g []Guess
+	for _, e := range snowflakeEpochs {
+		if *snowflakeEpoch != "" && *snowflakeEpoch != e.name {
+			continue
+		}
+		t := time.UnixMilli(n>>22 + e.ms)
+		gg := dateGuess(t)
+		gg.guess = fmt.Sprintf("%s Snowflake ID created %s", strings.ToUpper(e.name[:1])+e.name[1:], gg.guess)
+		gg.additional = append([]string{
+			fmt.Sprintf(e.worker, n>>17&0x1f, n>>12&0x1f),
+			fmt.Sprintf(e.sequence, n&0xfff),
+		}, gg.additional...)
+		gg.source = "Snowflake ID"
+		if t.After(now()) {
+			gg.goodness = -10
+		} else {
+			gg.goodness = 50 + yearGoodness(t)
+		}
+		g = append(g, gg)
+	}
+	return g
+}
+
+// Dates and timestamps as they appear in file names and the like, most
+// specific first.  Layouts are applied after removing separators matched
+// by sepRE, so 20150926-112943 and 20150926T112943 are both read with
+// 20060102150405.  An empty layout means a UNIX timestamp.
+var embeddedPatterns = []struct {
+	re     *regexp.Regexp
+	layout string
+	day    bool
+}{
+	{regexp.MustCompile(`\d{8}[-_T]?\d{6}`), "20060102150405", false},
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}`), "2006-01-02", true},
+	{regexp.MustCompile(`\d{8}`), "20060102", true},
+	{regexp.MustCompile(`\d{13}|\d{10}`), "", false},
+}
+
+var embeddedSepRE = regexp.MustCompile(`^(\d{8})[-_T](\d{6})$`)
+
+// A unit right after an embedded timestamp, as in 1443346122ms.
+var embeddedUnitRE = regexp.MustCompile(`^(?:s|ms|us|µs|ns)(?:[^\pL]|$)`)
+
+// guessEmbeddedTimestamp finds dates and UNIX timestamps inside longer
+// strings like backup-20150926-112943.tar.gz or log.1443270583.  Only
+// matches not surrounded by more digits count, and only the most specific
+// pattern that matches anything is used.  Such extraction is guesswork,
+// so the goodness stays modest, and the guess names the matched text.
+// Strings with colons or spaces are left alone, as those are usually
+// dates with a time of day, which other guessers read as a whole.
+func guessEmbeddedTimestamp(s string) []Guess {
+	if strings.ContainsAny(s, ": \t") {
+		return nil
+	}
+	isDigit := func(i int) bool { return i >= 0 && i < len(s) && s[i] >= '0' && s[i] <= '9' }
+	for _, p := range embeddedPatterns {
+		var gs []Guess
+		for _, loc := range p.re.FindAllStringIndex(s, -1) {
+			m := s[loc[0]:loc[1]]
+			if m == s || isDigit(loc[0]-1) || isDigit(loc[1]) {
+				continue
+			}
+			var t time.Time
+			var g Guess
+			if p.layout == "" {
+				n, err := strconv.ParseInt(m, 10, 64)
+				if err != nil {
+					continue
+				}
+				if embeddedUnitRE.MatchString(s[loc[1]:]) {
+					// Left to guessTimestampWithUnit.
+					continue
+				}
+				// guessTimestamp tries seconds, then milliseconds.
+				unit := 0
+				t = time.Unix(n, 0)
+				if len(m) == 13 {
+					unit = 1
+					t = time.UnixMilli(n)
+				}
+				ts := guessTimestamp(n)
+				if len(ts) <= unit || t.Year() < 1990 {
+					continue
+				}
+				g = ts[unit]
+				g.guess = "Embedded t" + strings.TrimPrefix(g.guess, "T")
+			} else {
+				var err error
+				t, err = time.ParseInLocation(p.layout, embeddedSepRE.ReplaceAllString(m, "$1$2"), time.Local)
+				if err != nil {
+					trace("%q in %q is not a date: %v", m, s, err)
+					continue
+				}
+				if p.day {
+					g = dayGuess(t)
+				} else {
+					g = dateGuess(t)
+				}
+				g.guess = fmt.Sprintf("Embedded date %s is %s", m, g.guess)
+			}
+			if t.Year() < 1990 || t.After(now().AddDate(10, 0, 0)) {
+				continue
+			}
+			g.source = "timestamp embedded in text"
+			g.goodness = yearGoodness(t) + 20
+			gs = append(gs, g)
+		}
+		if gs != nil {
+			return gs
+		}
+	}
+	return nil
+}
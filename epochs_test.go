@@ -0,0 +1,163 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGuessEpochDay(t *testing.T) {
+	gs := guessEpochDay("16704")
+	if len(gs) != 1 {
+		t.Fatalf("guessEpochDay() = %+v, want one guess", gs)
+	}
+	g := gs[0]
+	if want := "Epoch day 16704 is Saturday, 2015-09-26"; g.guess != want {
+		t.Errorf("guess = %q, want %q", g.guess, want)
+	}
+	if g.goodness != 20 {
+		t.Errorf("goodness = %d, want 20", g.goodness)
+	}
+	if gs := guessEpochDay("1"); len(gs) != 1 || gs[0].goodness >= 0 {
+		t.Errorf("guessEpochDay(1) = %+v, want an unlikely guess", gs)
+	}
+	for _, s := range []string{"0", "-5", "50000", "1.5"} {
+		if gs := guessEpochDay(s); gs != nil {
+			t.Errorf("guessEpochDay(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessDOSDate(t *testing.T) {
+	// 2015-09-26 is 35<<9 | 9<<5 | 26, 11:29:42 is 11<<11 | 29<<5 | 21.
+	for _, tc := range []struct {
+		in      string
+		guesses []string
+	}{
+		{"0x473a", []string{"DOS date 0x473a is Saturday, 2015-09-26", "DOS time 0x473a is 08:57:52"}},
+		{"0x5bb5", []string{"DOS time 0x5bb5 is 11:29:42"}},
+		{"0x473a5bb5", []string{"DOS timestamp 0x473a5bb5 is 2015-09-26 11:29:42 +0000 UTC"}},
+		{"18234", []string{"DOS date 0x473a is Saturday, 2015-09-26", "DOS time 0x473a is 08:57:52"}},
+	} {
+		gs := guessDOSDate(tc.in)
+		var got []string
+		for _, g := range gs {
+			got = append(got, g.guess)
+		}
+		if !reflect.DeepEqual(got, tc.guesses) {
+			t.Errorf("guessDOSDate(%q) = %q, want %q", tc.in, got, tc.guesses)
+		}
+	}
+	for _, s := range []string{"0", "0x1f", "0x473aff00", "abc", "0b100011100111010", "0o43472", "18_234"} {
+		if gs := guessDOSDate(s); gs != nil {
+			t.Errorf("guessDOSDate(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessJulianDay(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess, jd, mjd string
+	}{
+		{"2451545", "Julian day 2451545 is 2000-01-01 12:00:00 +0000 UTC", "JD 2451545, JDN 2451545", "MJD 51544.5"},
+		{"2457291.5", "Julian day 2457291.5 is 2015-09-26 00:00:00 +0000 UTC", "JD 2457291.5, JDN 2457291", "MJD 57291"},
+		{"57291", "Modified Julian day 57291 is 2015-09-26 00:00:00 +0000 UTC", "JD 2457291.5, JDN 2457291", "MJD 57291"},
+	} {
+		gs := guessJulianDay(tc.in)
+		if len(gs) != 1 {
+			t.Errorf("guessJulianDay(%q) = %+v, want one guess", tc.in, gs)
+			continue
+		}
+		g := gs[0]
+		if g.guess != tc.guess || g.additional[0] != tc.jd || g.additional[1] != tc.mjd {
+			t.Errorf("guessJulianDay(%q) = %q, %q, want %q, %q, %q", tc.in, g.guess, g.additional[:2], tc.guess, tc.jd, tc.mjd)
+		}
+	}
+	for _, s := range []string{"1000", "2400000", "3000000", "x"} {
+		if gs := guessJulianDay(s); gs != nil {
+			t.Errorf("guessJulianDay(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessExcelDate(t *testing.T) {
+	for _, tc := range []struct {
+		in      string
+		guesses []string
+	}{
+		{"42273", []string{"Spreadsheet date 42273 is Saturday, 2015-09-26", "Spreadsheet date 42273 is Friday, 2019-09-27"}},
+		{"42273.75", []string{"Spreadsheet date 42273.75 is 2015-09-26 18:00:00 +0000 UTC", "Spreadsheet date 42273.75 is 2019-09-27 18:00:00 +0000 UTC"}},
+		{"59", []string{"Spreadsheet date 59 is Wednesday, 1900-02-28", "Spreadsheet date 59 is Monday, 1904-02-29"}},
+		{"60", []string{"Spreadsheet date 60 is Tuesday, 1904-03-01"}},
+		{"61", []string{"Spreadsheet date 61 is Thursday, 1900-03-01", "Spreadsheet date 61 is Wednesday, 1904-03-02"}},
+	} {
+		var got []string
+		for _, g := range guessExcelDate(tc.in) {
+			got = append(got, g.guess)
+		}
+		if !reflect.DeepEqual(got, tc.guesses) {
+			t.Errorf("guessExcelDate(%q) = %q, want %q", tc.in, got, tc.guesses)
+		}
+	}
+	for _, s := range []string{"0", "0.5", "-1", "100000"} {
+		if gs := guessExcelDate(s); gs != nil {
+			t.Errorf("guessExcelDate(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessSnowflake(t *testing.T) {
+	gs := guessSnowflake("638501520799068202")
+	if len(gs) != 2 {
+		t.Fatalf("guessSnowflake() = %+v, want Twitter and Discord guesses", gs)
+	}
+	want := []string{"datacenter 3, worker 7", "sequence 42"}
+	if g := gs[0]; g.guess != "Twitter Snowflake ID created 2015-09-01 00:00:00 +0000 UTC" || !reflect.DeepEqual(g.additional[:2], want) || g.goodness != 80 {
+		t.Errorf("guessSnowflake()[0] = %+v", g)
+	}
+	if g := gs[1]; g.goodness != -10 {
+		t.Errorf("guessSnowflake()[1] = %+v, want goodness -10 for a date in the future", g)
+	}
+
+	setFlag(t, "snowflake-epoch", "discord")
+	gs = guessSnowflake("175928847299117063")
+	if len(gs) != 1 || gs[0].guess != "Discord Snowflake ID created 2016-04-30 11:18:25.796 +0000 UTC" {
+		t.Errorf("guessSnowflake() with -snowflake-epoch discord = %+v", gs)
+	}
+
+	for _, s := range []string{"1443346122085", "-638501520799068202", "abc"} {
+		if gs := guessSnowflake(s); gs != nil {
+			t.Errorf("guessSnowflake(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessEmbeddedTimestamp(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want []string
+	}{
+		{"backup-20150926-112943.tar.gz", []string{"Embedded date 20150926-112943 is 2015-09-26 11:29:43 +0000 UTC"}},
+		{"IMG_20150926_112943.jpg", []string{"Embedded date 20150926_112943 is 2015-09-26 11:29:43 +0000 UTC"}},
+		{"report_2015-09-26.pdf", []string{"Embedded date 2015-09-26 is Saturday, 2015-09-26"}},
+		{"dump-20150926.sql", []string{"Embedded date 20150926 is Saturday, 2015-09-26"}},
+		{"log.1443270583", []string{"Embedded timestamp 1443270583 is 2015-09-26 12:29:43 +0000 UTC"}},
+		{"trace-1443270583085.json", []string{"Embedded timestamp 1443270583085 is 2015-09-26 12:29:43.085 +0000 UTC"}},
+		{"2015-09-20..2015-09-26", []string{"Embedded date 2015-09-20 is Sunday, 2015-09-20", "Embedded date 2015-09-26 is Saturday, 2015-09-26"}},
+	} {
+		var got []string
+		for _, g := range guessEmbeddedTimestamp(tc.in) {
+			got = append(got, g.guess)
+			if g.goodness > 50 || g.source != "timestamp embedded in text" {
+				t.Errorf("guessEmbeddedTimestamp(%q) = %+v, want modest goodness", tc.in, g)
+			}
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("guessEmbeddedTimestamp(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+	for _, s := range []string{"2015-09-26", "1443270583", "foo-123456789012", "id-99999999", "v1.2.3", "order-5000000000-x", "2015-09-26 11:29:43", "at 2015-09-26", "log.0000000000", "x.0000000000000", "1443346122ms", "log-1443346122s.gz", "1443346122345us"} {
+		if gs := guessEmbeddedTimestamp(s); gs != nil {
+			t.Errorf("guessEmbeddedTimestamp(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
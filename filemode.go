@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// One clause of a symbolic chmod mode like u+rwx or go-w, see chmod(1).
+// Unlike chmod, we insist on the who part so that random words don't match.
+var (
+	chmodClauseRE = regexp.MustCompile(`^[ugoa]+(?:[-+=][rwxXst]*)+$`)
+	chmodOpRE     = regexp.MustCompile(`[-+=][rwxXst]*`)
+)
+
+// Permission bits for each who, and the special bits that s and t set.
+var chmodWho = map[byte]struct{ rwx, s uint32 }{
+	'u': {0700, 04000},
+	'g': {0070, 02000},
+	'o': {0007, 0},
+}
+
+// formatMode renders permission bits like ls does, e.g. rwxr-sr-t.
+func formatMode(mode uint32) string {
+	b := []byte("rwxrwxrwx")
+	for i := range b {
+		if mode&(1<<(8-i)) == 0 {
+			b[i] = '-'
+		}
+	}
+	for _, sp := range []struct {
+		bit      uint32
+		pos      int
+		set, nox byte
+	}{
+		{04000, 2, 's', 'S'},
+		{02000, 5, 's', 'S'},
+		{01000, 8, 't', 'T'},
+	} {
+		if mode&sp.bit == 0 {
+			continue
+		}
+		if b[sp.pos] == 'x' {
+			b[sp.pos] = sp.set
+		} else {
+			b[sp.pos] = sp.nox
+		}
+	}
+	return string(b)
+}
+
+// applyChmod applies a symbolic mode like u+rwx,go-w to mode, or returns
+// false if expr isn't one.
+func applyChmod(expr string, mode uint32) (uint32, bool) {
+	for _, clause := range strings.Split(expr, ",") {
+		if !chmodClauseRE.MatchString(clause) {
+			return 0, false
+		}
+		i := strings.IndexAny(clause, "+-=")
+		who := clause[:i]
+		if strings.Contains(who, "a") {
+			who = "ugo"
+		}
+		for _, op := range chmodOpRE.FindAllString(clause[i:], -1) {
+			var bits uint32
+			for _, w := range []byte(who) {
+				wb := chmodWho[w]
+				for _, p := range op[1:] {
+					switch p {
+					case 'r':
+						bits |= wb.rwx & 0444
+					case 'w':
+						bits |= wb.rwx & 0222
+					case 'x':
+						bits |= wb.rwx & 0111
+					case 'X':
+						if mode&0111 != 0 {
+							bits |= wb.rwx & 0111
+						}
+					case 's':
+						bits |= wb.s
+					case 't':
+						if w == 'o' {
+							bits |= 01000
+						}
+					}
+				}
+			}
+			switch op[0] {
+			case '+':
+				mode |= bits
+			case '-':
+				mode &^= bits
+			case '=':
+				var clear uint32
+				for _, w := range []byte(who) {
+					clear |= chmodWho[w].rwx | chmodWho[w].s
+				}
+				if strings.Contains(who, "o") {
+					clear |= 01000
+				}
+				mode = mode&^clear | bits
+			}
+		}
+	}
+	return mode, true
+}
+
+// guessChmod explains symbolic chmod modes by applying them to -base.
+func guessChmod(s string) []Guess {
+	base, err := strconv.ParseUint(*chmodBase, 8, 12)
+	if err != nil {
+		trace("invalid -base %q: %v", *chmodBase, err)
+		return nil
+	}
+	mode, ok := applyChmod(s, uint32(base))
+	if !ok {
+		return nil
+	}
+	return []Guess{{
+		guess:    fmt.Sprintf("chmod %s gives mode %04o (%s)", s, mode, formatMode(mode)),
+		comment:  fmt.Sprintf("applied to %04o (%s), see -base", base, formatMode(uint32(base))),
+		source:   "symbolic chmod mode",
+		goodness: 150,
+	}}
+}
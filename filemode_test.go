@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestGuessChmod(t *testing.T) {
+	for _, tc := range []struct {
+		in, base, guess string
+	}{
+		{"u+rwx,go-w", "000", "chmod u+rwx,go-w gives mode 0700 (rwx------)"},
+		{"a+r", "000", "chmod a+r gives mode 0444 (r--r--r--)"},
+		{"go-w", "0666", "chmod go-w gives mode 0644 (rw-r--r--)"},
+		{"u=rw,go=r", "0777", "chmod u=rw,go=r gives mode 0644 (rw-r--r--)"},
+		{"a+X", "0644", "chmod a+X gives mode 0644 (rw-r--r--)"},
+		{"a+X", "0744", "chmod a+X gives mode 0755 (rwxr-xr-x)"},
+		{"u+s,o+t", "0755", "chmod u+s,o+t gives mode 5755 (rwsr-xr-t)"},
+		{"g+s", "0640", "chmod g+s gives mode 2640 (rw-r-S---)"},
+		{"u+x-w", "0644", "chmod u+x-w gives mode 0544 (r-xr--r--)"},
+	} {
+		setFlag(t, "base", tc.base)
+		gs := guessChmod(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess {
+			t.Errorf("guessChmod(%q) with -base %s = %+v, want %q", tc.in, tc.base, gs, tc.guess)
+		}
+	}
+	setFlag(t, "base", "000")
+	for _, s := range []string{"+x", "rwx", "u", "u+rwz", "user+x", "u+x,", "755"} {
+		if gs := guessChmod(s); gs != nil {
+			t.Errorf("guessChmod(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
@@ -2,10 +2,14 @@ module guess
 
 go 1.21.0
 
-require github.com/fatih/color v1.15.0
+require (
+	github.com/fatih/color v1.15.0
+	github.com/mattn/go-isatty v0.0.17
+	golang.org/x/net v0.17.0
+	golang.org/x/text v0.13.0
+)
 
 require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
-	github.com/mattn/go-isatty v0.0.17 // indirect
-	golang.org/x/sys v0.6.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
 )
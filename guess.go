@@ -1,21 +1,36 @@
 package main
 
 import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
+	"math"
 	"net"
 	"os"
+	"path/filepath"
+	"runtime/debug"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+
+	"guess/bytesize"
 )
 
 var (
 	doTrace       = flag.Bool("trace", false, "Trace program execution")
+	traceFormat   = flag.String("trace-format", "text", "Format of -trace output: text, logfmt for key=value pairs or json")
 	verbose       = flag.Bool("verbose", false, "Print more information")
 	printUnlikely = flag.Bool("unlikely", false, "Also show unlikely matches")
 	sortGuesses   = flag.Bool("sort", true, "Sort guesses by likeliness")
@@ -24,6 +39,49 @@ var (
 		"Timezones that to convert to/from for timestamps and dates")
 	alwaysCalendar = flag.Bool("calendar", false, "Always display a calendar alongside dates")
 	pangoMarkup    = flag.Bool("pango_markup", false, "Use Pango markup instead of ANSI color sequences")
+	offline        = flag.Bool("offline", false, "Do not perform any network lookups")
+	netTimeout     = flag.Duration("timeout", 2*time.Second, "Timeout for network lookups")
+	decodeAs       = flag.String("decode", "", "Decode the input from hex, base64 or base32 before guessing")
+	jsonOutput     = flag.Bool("json", false, "Print guesses as a JSON document")
+	jsonStream     = flag.Bool("json-stream", false, "Print guesses as newline-delimited JSON, one object per guess")
+	tableOutput    = flag.Bool("table", false, "Print guesses as a table with one line per guess and no details")
+	markdown       = flag.Bool("markdown", false, "Print guesses as Markdown for pasting into issues and docs")
+	diffMode       = flag.Bool("diff", false, "Compare two dates, durations or byte sizes given as arguments")
+	usePager       = flag.Bool("pager", false, "Show the guesses in $PAGER or less when writing to a terminal")
+	fieldPath      = flag.String("field", "", "Guess the value at this dotted path, like items.0.exp, in the JSON input instead of the whole input")
+	weekInfo       = flag.Bool("week", false, "Also show the quarter, ISO week and day of the year of dates")
+	showMoon       = flag.Bool("moon", false, "Also show the phase of the moon for dates")
+	explainTZ      = flag.Bool("explain-timezone", false, "Also tell for each time zone whether the date is in standard or daylight saving time, and its abbreviation and offset now where those differ")
+	stable         = flag.Bool("stable", false, "Leave out relative times like \"2 days ago\", the highlighting of today and, unless -now is given, guesses relative to the current time, e.g. for snapshot tests")
+	anchorFlag     = flag.String("now", "", "Compare dates against this timestamp or date instead of the current time")
+	locale         = flag.String("locale", "en", "Language for month and weekday names, e.g. de or pt-BR")
+	hourClock      = flag.Int("clock", 24, "Show times with a 12 or 24 hour clock")
+	pluginDir      = flag.String("plugins", defaultPluginDir(), "Directory of executables to run as additional guessers")
+	pluginTimeout  = flag.Duration("plugin-timeout", 2*time.Second, "Timeout for each plugin")
+	tzOffsetsOnly  = flag.Bool("format-time-zone-only-offsets", false, "Show dates in other timezones as just the time of day and UTC offset")
+	sortTZs        = flag.Bool("sort-timezones", false, "List timezones by their UTC offset instead of in the order given")
+	prefer         = flag.String("prefer", "", "Rank guesses of this kind, e.g. timestamp, first")
+	preferBonus    = flag.Int("prefer-bonus", 250, "How much to add to the goodness of guesses of the -prefer kind")
+	only           = flag.String("only", "", "Only run these comma-separated guessers, e.g. timestamp,date")
+	literal        = flag.Bool("literal", false, "Guess the input exactly as given, without trimming white space or reading digit grouping separators in numbers")
+	decimalSep     = flag.String("decimal-separator", ".", "Decimal separator in numbers, the other of , and . is taken to group digits")
+	fps            = flag.Float64("fps", 0, "Frame rate for SMPTE timecodes, e.g. 25 or 29.97; by default a few common ones are shown")
+	rootPx         = flag.Float64("root-px", 16, "Root font size in pixels for CSS rem and em units")
+	goodnessCap    = flag.Bool("max-goodness-cap", false, "With -verbose, also show goodness as a percentage from the worst (0) to the best guess (100)")
+	yearPivot      = flag.Int("year-pivot", 69, "Two-digit years below this are taken to be in the 2000s, others in the 1900s")
+	relativeStyle  = flag.String("relative-style", "exact", "How to describe how far dates are from now: exact (\"2 days 3 hours ago\"), rounded (\"about 2 days ago\") or terse (\"2d ago\")")
+	outputFile     = flag.String("output", "", "Write the guesses to this file instead of standard output, without colors")
+	idsMode        = flag.Bool("ids", false, "Also interpret integers as Unix user and group IDs")
+	chmodBase      = flag.String("base", "000", "Octal file mode that symbolic chmod modes like u+x are applied to")
+	fromClipboard  = flag.Bool("clipboard", false, "Guess what's on the clipboard instead of the argument")
+	snowflakeEpoch = flag.String("snowflake-epoch", "", "Only decode Snowflake IDs with this epoch, twitter or discord")
+	flagsMode      = flag.Bool("flags", false, "Also interpret 0, 1 and -1 as booleans and tri-state flags")
+	otherBases     = flag.Bool("bases", false, "Also read decimal integers as binary, octal and hexadecimal numbers without their prefix")
+	asciiOnly      = flag.Bool("ascii", false, "Only print ASCII characters")
+	sunAt          = flag.String("at", "", "Show sunrise and sunset for dates at these LAT,LON coordinates")
+	timeFormat     = flag.String("time-format", "", "Show times in this Go reference time layout or named layout like RFC3339, overriding -clock")
+	completion     = flag.String("completion", "", "Print a completion script for bash, zsh or fish")
+	showVersion    = flag.Bool("version", false, "Print the version and build information and exit")
 )
 
 var (
@@ -86,27 +144,114 @@ var (
 		"Jan 2 2006",
 		"January 2 2006",
 	}
+	// Two-digit years, whose century is decided by -year-pivot
+	twoDigitYearFormats = []string{
+		"01/02/06 15:04:05",
+		"02/01/06 15:04:05",
+		"01/02/06",
+		"02/01/06",
+		"02.01.06",
+		"02-Jan-06",
+	}
 )
 
-var byteUnits = []struct {
-	mult, altMult int
-	sym, altSym   string
-	alias         string
-}{
-	{1024, 1000, "KiB", "KB", "K"},
-	{1024 * 1024, 1000 * 1000, "MiB", "MB", "M"},
-	{1024 * 1024 * 1024, 1000 * 1000 * 1000, "GiB", "GB", "G"},
-	{1024 * 1024 * 1024 * 1024, 1000 * 1000 * 1000 * 1000, "TiB", "TB", "T"},
-	{1024 * 1024 * 1024 * 1024 * 1024, 1000 * 1000 * 1000 * 1000 * 1000, "PiB", "PB", "P"},
-	{1024 * 1024 * 1024 * 1024 * 1024 * 1024, 1000 * 1000 * 1000 * 1000 * 1000 * 1000, "EiB", "EB", "E"},
+// Date layouts given with -date-formats.
+var customFormats []string
+
+func init() {
+	flag.BoolVar(fromClipboard, "c", false, "Shorthand for -clipboard")
+	flag.Func("date-formats", "Also parse dates in this Go reference time layout, e.g. \"02.01.2006 15h04\"; may be given more than once", addDateFormat)
+	flag.Func("adjust", "Change the goodness of guesses whose source contains SOURCE with SOURCE=+N, SOURCE=-N or SOURCE=*F, e.g. \"byte count=-100\"; may be given more than once", addAdjustment)
+}
+
+// addDateFormat adds a layout for -date-formats if it formats times in a
+// way that it can parse again.
+func addDateFormat(layout string) error {
+	t := clock()
+	s := t.Format(layout)
+	if s == layout {
+		return fmt.Errorf("%q does not contain any parts of the reference time Mon Jan 2 15:04:05 MST 2006", layout)
+	}
+	if _, err := time.Parse(layout, s); err != nil {
+		return fmt.Errorf("%q cannot be parsed back: %v", layout, err)
+	}
+	customFormats = append(customFormats, layout)
+	return nil
+}
+
+// The reference time given with -now, zero if dates are compared against
+// the current time.
+var anchor time.Time
+
+// clock returns the current time.  Tests replace it to get deterministic
+// output; everything else should call now() instead.
+var clock = time.Now
+
+// now returns the time that dates are compared against.
+func now() time.Time {
+	if !anchor.IsZero() {
+		return anchor
+	}
+	return clock()
+}
+
+// unanchored is true with -stable but without -now, when guesses that are
+// only defined relative to the current time, like "3 days ago" or a bare
+// time of day, would differ from one run to the next and are left out.
+func unanchored() bool {
+	return *stable && anchor.IsZero()
+}
+
+// parseAnchor parses the argument of the -now flag, which may be a UNIX
+// timestamp or a date in any of the formats we recognize.
+func parseAnchor(s string) (time.Time, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(n, 0), nil
+	}
+	for _, format := range goodTZformats {
+		if t, err := time.Parse(format, s); err == nil {
+			return t, nil
+		}
+	}
+	for _, format := range badTZformats {
+		if t, err := time.ParseInLocation(format, s, time.Local); err == nil && t.Year() != 0 {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as reference time", s)
 }
 
-var Trace *log.Logger
+// Trace receives the -trace output, see newTraceLogger.
+var Trace *slog.Logger
+
+// lastTrace is the latest message traced, kept with -verbose so that the
+// summary of attempts can tell why a guesser didn't match.
+var lastTrace string
 
 func trace(s string, args ...interface{}) {
-	if *doTrace && Trace != nil {
-		Trace.Printf(s, args...)
+	tracing := *doTrace && Trace != nil
+	if !tracing && !*verbose {
+		return
 	}
+	msg := fmt.Sprintf(s, args...)
+	lastTrace = msg
+	if tracing {
+		Trace.Debug(msg)
+	}
+}
+
+// traceAttrs traces msg with key/value pairs, which -trace-format logfmt and
+// json keep as separate fields.  The default text format has the sentence
+// text instead, which says the same.
+func traceAttrs(text, msg string, args ...interface{}) {
+	if !*doTrace || Trace == nil {
+		return
+	}
+	if *traceFormat == "text" {
+		Trace.Debug(text)
+		return
+	}
+	Trace.Debug(msg, args...)
 }
 
 // For highlighting important parts via ANSI color sequences or Pango markup
@@ -117,6 +262,7 @@ type Guess struct {
 	additional     []string
 	source         string
 	goodness       int
+	percent        int // goodness scaled to 0-100, see normalizeGoodness
 }
 
 func (g *Guess) String() string {
@@ -130,10 +276,16 @@ func (g *Guess) String() string {
 		}
 	}
 	v := ""
-	if *verbose {
+	if *verbose && *goodnessCap {
+		v = fmt.Sprintf("[goodness: %d (%d%%), source: %s]\n", g.goodness, g.percent, g.source)
+	} else if *verbose {
 		v = fmt.Sprintf("[goodness: %d, source: %s]\n", g.goodness, g.source)
 	}
-	return v + cHighlight(t) + c + "\n" + a
+	out := v + cHighlight(t) + c + "\n" + a
+	if *asciiOnly {
+		return toASCII(out)
+	}
+	return out
 }
 
 type ByGoodness []Guess
@@ -142,24 +294,464 @@ func (gs ByGoodness) Len() int           { return len(gs) }
 func (gs ByGoodness) Less(i, j int) bool { return gs[i].goodness > gs[j].goodness }
 func (gs ByGoodness) Swap(i, j int)      { gs[i], gs[j] = gs[j], gs[i] }
 
+// An adjustment changes the goodness of guesses whose source contains
+// match, ignoring case, by multiplying it with mult and then adding add.
+type adjustment struct {
+	match string
+	mult  float64
+	add   int
+}
+
+// Adjustments given with -adjust, applied in that order.
+var adjustments []adjustment
+
+func addAdjustment(s string) error {
+	i := strings.LastIndex(s, "=")
+	if i <= 0 {
+		return fmt.Errorf("%q is not SOURCE=+N, SOURCE=-N or SOURCE=*F", s)
+	}
+	a := adjustment{match: strings.ToLower(s[:i]), mult: 1}
+	v := s[i+1:]
+	var err error
+	if strings.HasPrefix(v, "*") {
+		a.mult, err = strconv.ParseFloat(v[1:], 64)
+	} else {
+		a.add, err = strconv.Atoi(v)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid adjustment %q in %q", v, s)
+	}
+	adjustments = append(adjustments, a)
+	return nil
+}
+
+// adjustGoodness applies the -adjust adjustments to gs.
+func adjustGoodness(gs []Guess) {
+	for i := range gs {
+		source := strings.ToLower(gs[i].source)
+		for _, a := range adjustments {
+			if strings.Contains(source, a.match) {
+				gs[i].goodness = int(math.Round(float64(gs[i].goodness)*a.mult)) + a.add
+			}
+		}
+	}
+}
+
+// normalizeGoodness scales the goodness of guesses to percentages, with
+// 100 for the best guess and 0 for the worst, which is easier to read than
+// the raw numbers.
+func normalizeGoodness(gs []Guess) {
+	if len(gs) == 0 {
+		return
+	}
+	min, max := gs[0].goodness, gs[0].goodness
+	for _, g := range gs {
+		if g.goodness < min {
+			min = g.goodness
+		}
+		if g.goodness > max {
+			max = g.goodness
+		}
+	}
+	for i := range gs {
+		if max == min {
+			gs[i].percent = 100
+			continue
+		}
+		gs[i].percent = (gs[i].goodness - min) * 100 / (max - min)
+	}
+}
+
 // TODO: It might be interesting to also define a type GuessGroup []Guess, and
 // then sort within the group, and sort a []GuessGroup collection by e.g.
 // maximum element or sum of guesses.
 
+// A guesser tries to interpret the input in one particular way.  It returns
+// nil if the input cannot be interpreted that way.
+type guesser struct {
+	name string
+	fn   func(s string) []Guess
+}
+
+// guessers lists all the ways we try to interpret the input, in the order in
+// which their guesses are shown when there's no sorting.
+var guessers = []guesser{
+	{"bytes", guessBytes},
+	{"timestamp", guessTimestampString},
+	{"epochday", guessEpochDay},
+	{"dos", guessDOSDate},
+	{"julian", guessJulianDay},
+	{"excel", guessExcelDate},
+	{"snowflake", guessSnowflake},
+	{"embedded", guessEmbeddedTimestamp},
+	{"keyword", guessKeyword},
+	{"relative", guessRelative},
+	{"date", guessDate},
+	{"ical", guessICalDate},
+	{"pgtimestamp", guessPGTimestamp},
+	{"time", guessTimeOnly},
+	{"duration", guessDuration},
+	{"interval", guessPGInterval},
+	{"timecode", guessTimecode},
+	{"ip", guessIPString},
+	{"packedip", guessPackedIP},
+	{"hexip", guessHexIP},
+	{"asn", guessASN},
+	{"basicauth", guessBasicAuth},
+	{"userpass", guessUserPass},
+	{"email", guessEmail},
+	{"domain", guessDomain},
+	{"git", guessGitSHA},
+	{"container", guessContainerID},
+	{"bic", guessBIC},
+	{"vin", guessVIN},
+	{"sid", guessSID},
+	{"ulid", guessULID},
+	{"crockford", guessCrockford},
+	{"objectid", guessObjectID},
+	{"chmod", guessChmod},
+	{"count", guessCount},
+	{"range", guessRange},
+	{"si", guessSIQuantity},
+	{"throughput", guessThroughput},
+	{"angle", guessAngle},
+	{"k8s", guessK8sQuantity},
+	{"css", guessCSSUnit},
+	{"color", guessCSSColor},
+	{"flag", guessFlagValue},
+	{"uid", guessUnixID},
+	{"json", guessJSON},
+	{"base64json", guessBase64JSON},
+	{"bytesequence", guessByteSequence},
+	{"hex", guessHexInt},
+	{"bcd", guessBCD},
+	{"gray", guessGrayCode},
+	{"bases", guessOtherBases},
+	{"percent", guessPercentEncoded},
+	{"encodedword", guessEncodedWords},
+	{"quotedprintable", guessQuotedPrintable},
+	{"ansi", guessANSI},
+	{"morse", guessMorse},
+	{"braille", guessBraille},
+	{"text", guessText},
+	{"plugins", guessPlugins},
+}
+
 func guess(s string) []Guess {
+	g, _ := tryGuessers(s)
+	return g
+}
+
+// An attempt records how one guesser fared with the input.
+type attempt struct {
+	name     string
+	likely   int // guesses with goodness >= 0
+	unlikely int
+	reason   string // why there were no guesses, if the guesser said
+}
+
+// onlyGuessers returns the guessers named by -only, or nil for all.
+func onlyGuessers() map[string]bool {
+	if *only == "" {
+		return nil
+	}
+	names := map[string]bool{}
+	for _, name := range strings.Split(*only, ",") {
+		names[strings.TrimSpace(name)] = true
+	}
+	return names
+}
+
+// tryGuessers runs the guessers on s, all of them or those given with
+// -only, returning their guesses and how each of them did.
+func tryGuessers(s string) ([]Guess, []attempt) {
 	var g []Guess
-	if n, err := strconv.Atoi(s); err == nil {
+	var as []attempt
+	names := onlyGuessers()
+	for _, gg := range guessers {
+		if names != nil && !names[gg.name] {
+			continue
+		}
+		lastTrace = ""
+		gs := gg.fn(s)
+		if gg.name == *prefer {
+			for i := range gs {
+				gs[i].goodness += *preferBonus
+			}
+		}
+		a := attempt{name: gg.name}
+		for _, x := range gs {
+			if x.goodness >= 0 {
+				a.likely++
+			} else {
+				a.unlikely++
+			}
+		}
+		if len(gs) == 0 {
+			a.reason = lastTrace
+		}
+		traceAttrs(fmt.Sprintf("guesser %s: %d likely, %d unlikely guesses", gg.name, a.likely, a.unlikely),
+			"guesser done", "guesser", gg.name, "likely", a.likely, "unlikely", a.unlikely)
+		g = append(g, gs...)
+		as = append(as, a)
+	}
+	return g, as
+}
+
+// summarizeAttempts describes which guessers matched and which didn't, and
+// why where they said, so -verbose can explain why an input wasn't
+// recognized.
+func summarizeAttempts(as []attempt) string {
+	var matched, unlikely, failed []string
+	var reasons []string
+	for _, a := range as {
+		switch {
+		case a.likely > 0:
+			matched = append(matched, a.name)
+		case a.unlikely > 0:
+			unlikely = append(unlikely, a.name)
+		default:
+			failed = append(failed, a.name)
+			if a.reason != "" {
+				reasons = append(reasons, fmt.Sprintf("%s: %s", a.name, a.reason))
+			}
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Tried %d guessers:\n", len(as))
+	for _, l := range []struct {
+		what  string
+		names []string
+	}{
+		{"matched", matched},
+		{"only unlikely guesses (see -unlikely)", unlikely},
+		{"input not in their format", failed},
+	} {
+		if len(l.names) > 0 {
+			fmt.Fprintf(&b, "    %d %s: %s\n", len(l.names), l.what, strings.Join(l.names, ", "))
+		}
+	}
+	for _, r := range reasons {
+		fmt.Fprintf(&b, "        %s\n", r)
+	}
+	return b.String()
+}
+
+// checkGuesserName returns an error unless name is one of the guessers.
+func checkGuesserName(name string) error {
+	var names []string
+	for _, gg := range guessers {
+		if gg.name == name {
+			return nil
+		}
+		names = append(names, gg.name)
+	}
+	return fmt.Errorf("unknown guesser %q, must be one of %s", name, strings.Join(names, ", "))
+}
+
+// Separators people put between groups of three digits, as in 1,234,567.
+// Whichever one is the -decimal-separator is not a grouping separator.
+var groupSeparators = []string{",", ".", "'", " ", "\u2009", "\u202f"}
+
+// parseInt parses s as a decimal integer, which may have its digits
+// grouped in threes like 1,234,567 or, Go style, with underscores like
+// 1_000_000, unless -literal is given.
+func parseInt(s string) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err == nil || *literal {
+		return n, err
+	}
+	if strings.Contains(s, "_") {
+		// Base 0 allows underscores, but also prefixes like 0x and 0o.
+		if digits := strings.TrimPrefix(s, "-"); len(digits) > 1 && digits[0] == '0' {
+			return 0, err
+		}
+		return strconv.ParseInt(s, 0, 64)
+	}
+	for _, sep := range groupSeparators {
+		if sep == *decimalSep || !strings.Contains(s, sep) {
+			continue
+		}
+		groups := strings.Split(strings.TrimPrefix(s, "-"), sep)
+		if len(groups[0]) < 1 || len(groups[0]) > 3 {
+			return 0, err
+		}
+		for _, g := range groups[1:] {
+			if len(g) != 3 {
+				return 0, err
+			}
+		}
+		trace("parsing %q as integer with grouping separator %q", s, sep)
+		return strconv.ParseInt(strings.ReplaceAll(s, sep, ""), 10, 64)
+	}
+	return 0, err
+}
+
+// guessBytes interprets s as a number of bytes, either as a bare integer or
+// with a unit like KiB or MB.
+func guessBytes(s string) []Guess {
+	n, err := parseInt(s)
+	if err == nil {
 		trace("parsed as integer")
-		g = append(g, guessByteSize(n)...)
-		g = append(g, guessTimestamp(int64(n))...)
+		return guessByteSize(n)
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		return guessTooManyBytes(s)
+	}
+
+	n, err = bytesize.ParseBytes(s)
+	if errors.Is(err, bytesize.ErrTooLarge) {
+		return guessTooManyBytes(s)
+	}
+	if err != nil {
+		trace("cannot parse %s as byte count: %v", s, err)
+		return nil
+	}
+	return guessBytesWithUnit(n)
+}
+
+func guessTimestampString(s string) []Guess {
+	n, err := parseInt(s)
+	if err != nil {
+		return guessTimestampWithUnit(s)
+	}
+	return guessTimestamp(n)
+}
+
+// Unit suffixes that say how to read a timestamp, like 1443346122s.
+var timestampUnits = []struct {
+	suffix string
+	unit   time.Duration
+	src    string
+}{
+	{"ns", time.Nanosecond, "timestamp (nanoseconds)"},
+	{"us", time.Microsecond, "timestamp (microseconds)"},
+	{"µs", time.Microsecond, "timestamp (microseconds)"},
+	{"ms", time.Millisecond, "timestamp (milliseconds)"},
+	{"s", time.Second, "timestamp (seconds)"},
+}
+
+// guessTimestampWithUnit interprets timestamps whose unit is given, like
+// 1443346122085ms, only in that unit.  Small values like 30s are more
+// likely durations, so they are only ranked high if they're at least a
+// year after 1970.
+func guessTimestampWithUnit(s string) []Guess {
+	for _, u := range timestampUnits {
+		num := strings.TrimSuffix(s, u.suffix)
+		if num == s {
+			continue
+		}
+		n, err := strconv.ParseInt(num, 10, 64)
+		if err != nil || n <= 0 || n > math.MaxInt64/int64(u.unit) {
+			return nil
+		}
+		t := time.Unix(0, n*int64(u.unit))
+		g := dateGuess(t)
+		g.guess = fmt.Sprintf("Timestamp %s is %s", s, g.guess)
+		g.source = u.src + " with explicit unit"
+		if t.Year() > 1970 {
+			g.goodness = 200
+		}
+		return []Guess{g}
+	}
+	return nil
+}
+
+// guessDate tries all the date formats we know, and those given with
+// -date-formats.  Built-in formats without timezone are only tried if none
+// of the ones with timezone matched.
+func guessDate(s string) []Guess {
+	return append(guessCustomDate(s), guessBuiltinDate(s)...)
+}
+
+// guessCustomDate tries the -date-formats.
+func guessCustomDate(s string) []Guess {
+	var g []Guess
+	for _, format := range customFormats {
+		d, err := time.ParseInLocation(format, s, time.Local)
+		if err != nil {
+			trace("error parsing as date: %v", err)
+			continue
+		}
+		gg := dateGuess(d)
+		gg.source = fmt.Sprintf("date string with custom format %q", format)
+		g = append(g, gg)
+	}
+	return g
+}
+
+// zoneCache maps the time zone abbreviations used in TZs this year to the
+// zones using them, in the order of TZs.  It is rebuilt when TZs changes.
+var zoneCache struct {
+	tzs      []*time.Location
+	byAbbrev map[string][]*time.Location
+}
+
+// zonesWithAbbreviation returns the zones in TZs that might use the time
+// zone abbreviation z, so that dates with abbreviations don't have to be
+// checked against all of them.  For abbreviations not in use this year,
+// like those of historic dates, that's all of TZs.
+func zonesWithAbbreviation(z string) []*time.Location {
+	same := len(zoneCache.tzs) == len(TZs)
+	for i := 0; same && i < len(TZs); i++ {
+		same = zoneCache.tzs[i] == TZs[i]
+	}
+	if !same {
+		zoneCache.tzs = append([]*time.Location(nil), TZs...)
+		zoneCache.byAbbrev = map[string][]*time.Location{}
+		year := now().Year()
+		for _, loc := range TZs {
+			seen := map[string]bool{}
+			// Monthly samples catch both sides of daylight saving time.
+			for m := time.January; m <= time.December; m++ {
+				abbrev, _ := time.Date(year, m, 1, 0, 0, 0, 0, loc).Zone()
+				if !seen[abbrev] {
+					seen[abbrev] = true
+					zoneCache.byAbbrev[abbrev] = append(zoneCache.byAbbrev[abbrev], loc)
+				}
+			}
+		}
+	}
+	if locs, ok := zoneCache.byAbbrev[z]; ok {
+		return locs
 	}
+	return TZs
+}
 
-	if s == "now" {
-		g = append(g, guessTimestamp(time.Now().Unix())...)
+// layoutMayMatch cheaply rules out layouts that s cannot be in, which saves
+// most of the time.Parse calls for the long lists of layouts we try.  All
+// of them start with either a number or a month or weekday name, and the
+// input has to start the same way.  Spaces and slashes are never part of
+// layout elements, so the input needs them if the layout has them.
+func layoutMayMatch(layout, s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, sep := range []string{" ", "/"} {
+		if strings.Contains(layout, sep) && !strings.Contains(s, sep) {
+			return false
+		}
+	}
+	l, c := rune(layout[0]), rune(s[0])
+	switch {
+	case unicode.IsDigit(l):
+		return unicode.IsDigit(c)
+	case unicode.IsLetter(l):
+		return unicode.IsLetter(c)
 	}
+	return true
+}
 
-	founddate := false
+func guessBuiltinDate(s string) []Guess {
+	// Every built-in layout has a number in it somewhere.
+	if !strings.ContainsAny(s, "0123456789") {
+		return nil
+	}
+	var g []Guess
 	for _, format := range goodTZformats {
+		if !layoutMayMatch(format, s) {
+			continue
+		}
 		d, err := time.Parse(format, s)
 		if err != nil {
 			trace("error parsing as date: %v", err)
@@ -171,76 +763,171 @@ func guess(s string) []Guess {
 		// zero offset; knowing which time zones are interesting, we
 		// can do better here, e.g. we successfully parse
 		// 2015-09-26 11:29:43 PDT as 2015-09-26 11:29:43 -0700 PDT.
+		// Whether a zone uses the abbreviation is up to
+		// time.ParseInLocation, which looks at the parsed date rather
+		// than now, so a PST date is found in summer, too, and either
+		// side of a DST transition gets its own offset.
 		z, o := d.Zone()
-		if o == 0 {
-			for _, loc := range TZs {
-				cand, _ := d.In(loc).Zone()
-				if z != cand {
+		if o == 0 && z != "" {
+			for _, loc := range zonesWithAbbreviation(z) {
+				cand, err := time.ParseInLocation(format, s, loc)
+				// Abbreviations unknown to loc get a made-up
+				// zone with offset 0.
+				if err != nil || cand.Location() != loc {
 					continue
 				}
-				d, err = time.ParseInLocation(format, s, loc)
-				if err != nil {
-					panic(err)
-				}
+				d = cand
+				break
 			}
 		}
 		trace("successfully parsed date %q as %s", s, d)
 		gg := dateGuess(d)
 		gg.source = "date string with timezone"
 		g = append(g, gg)
-		founddate = true
 	}
-	if !founddate {
-		for _, format := range badTZformats {
-			t, err := time.ParseInLocation(format, s, time.Local)
-			if err != nil {
-				trace("error parsing as date: %v", err)
-				continue
-			}
-			trace("%q is parsable from format %q", s, format)
-			g = append(g, guessBadDate(format, s, t)...)
-		}
+	if g != nil {
+		return g
 	}
 
-	if ip := net.ParseIP(s); ip != nil {
-		trace("successfully parsed as IP address: %v", ip)
-		g = append(g, guessIP(ip)...)
+	for _, format := range badTZformats {
+		if !layoutMayMatch(format, s) {
+			continue
+		}
+		t, err := time.ParseInLocation(format, s, time.Local)
+		if err != nil {
+			trace("error parsing as date: %v", err)
+			continue
+		}
+		trace("%q is parsable from format %q", s, format)
+		g = append(g, guessBadDate(format, s, t, 0)...)
+	}
+	if g != nil {
+		return g
 	}
 
-	for _, i := range byteUnits {
-		mult := 0
-		switch {
-		case strings.HasSuffix(s, i.sym):
-			mult = i.mult
-			s = strings.TrimSuffix(s, i.sym)
-		case strings.HasSuffix(s, i.alias):
-			mult = i.mult
-			s = strings.TrimSuffix(s, i.alias)
-		case strings.HasSuffix(s, i.altSym):
-			mult = i.altMult
-			s = strings.TrimSuffix(s, i.altSym)
-		}
-		if mult == 0 {
+	for _, format := range twoDigitYearFormats {
+		if !layoutMayMatch(format, s) {
 			continue
 		}
-		s = strings.TrimSpace(s)
-		f, err := strconv.ParseFloat(s, 64)
+		t, err := time.ParseInLocation(format, s, time.Local)
 		if err != nil {
-			trace("cannot parse %s as float: %v", s, err)
+			trace("error parsing as date: %v", err)
 			continue
 		}
-		g = append(g, guessBytesWithUnit(mult, f)...)
+		trace("%q is parsable from format %q", s, format)
+		g = append(g, guessTwoDigitYear(format, s, t)...)
 	}
+	return g
+}
 
+// guessTwoDigitYear puts the year of d, parsed from a two-digit year, into
+// the 2000s if it's below -year-pivot and into the 1900s otherwise.  The
+// pivotWindow years on either side of the pivot are also guessed in the
+// other century.
+func guessTwoDigitYear(f, i string, d time.Time) []Guess {
+	const pivotWindow = 5
+	yy := d.Year() % 100
+	year, other := 1900+yy, 2000+yy
+	if yy < *yearPivot {
+		year, other = other, year
+	}
+	years := []int{year}
+	if *yearPivot-pivotWindow <= yy && yy < *yearPivot+pivotWindow {
+		years = append(years, other)
+	}
+	var g []Guess
+	for _, y := range years {
+		gs := guessBadDate(f, i, d, y-d.Year())
+		for j := range gs {
+			gs[j].additional = append([]string{fmt.Sprintf("two-digit year %02d taken as %d (-year-pivot %d)", yy, y, *yearPivot)}, gs[j].additional...)
+			if y != year {
+				gs[j].goodness -= 10
+			}
+		}
+		g = append(g, gs...)
+	}
 	return g
 }
 
-func guessBadDate(f, i string, d time.Time) []Guess {
+// ISO 8601 times of day without a date.
+var timeOnlyFormats = []string{
+	"15:04:05.999999999Z07:00",
+	"15:04Z07:00",
+	"15:04:05.999999999",
+	"15:04",
+}
+
+// onDay returns the given time of day on the day of d.
+func onDay(d, tod time.Time) time.Time {
+	return time.Date(d.Year(), d.Month(), d.Day(), tod.Hour(), tod.Minute(), tod.Second(), tod.Nanosecond(), d.Location())
+}
+
+// guessTimeOnly interprets a bare time of day as referring to today.
+func guessTimeOnly(s string) []Guess {
+	s = strings.TrimPrefix(s, "T")
+	for _, format := range timeOnlyFormats {
+		if !layoutMayMatch(format, s) {
+			continue
+		}
+		tod, err := time.Parse(format, s)
+		if err != nil {
+			continue
+		}
+		trace("%q is parsable as time of day from format %q", s, format)
+		if unanchored() {
+			trace("leaving out time of day %q relative to today with -stable, see -now", s)
+			return nil
+		}
+		if strings.Contains(format, "Z07") {
+			t := onDay(now().In(tod.Location()), tod)
+			g := dateGuess(t)
+			g.guess = "Today at " + g.guess
+			g.source = "time of day with offset"
+			g.goodness = 100
+			return []Guess{g}
+		}
+
+		today := onDay(now().In(time.Local), tod)
+		_, ds := deltaNow(today)
+		var lines []string
+		for _, loc := range TZs {
+			t := onDay(now().In(loc), tod)
+			zone, _ := t.Zone()
+			l := fmt.Sprintf("From %s (%s): %s", zone, loc, formatTime(t.Local()))
+			if _, d := deltaNow(t); d != "" {
+				l += fmt.Sprintf(" (%s)", d)
+			}
+			lines = append(lines, l)
+		}
+		return []Guess{{
+			guess:      "Today in local time: " + formatTime(today),
+			comment:    ds,
+			additional: lines,
+			source:     "time of day",
+			goodness:   100,
+		}}
+	}
+	return nil
+}
+
+func guessIPString(s string) []Guess {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil
+	}
+	trace("successfully parsed as IP address: %v", ip)
+	return guessIP(ip)
+}
+
+// guessBadDate guesses a date d parsed from i with format f, which has no
+// timezone.  The parsed year is corrected by yearShift.
+func guessBadDate(f, i string, d time.Time, yearShift int) []Guess {
 	var lines []string
 
 	// Date might be missing an explicit year, so we fabricate one.
-	curryear := time.Now().Year()
+	curryear := now().Year()
 	fixup := func(t *time.Time) {
+		*t = t.AddDate(yearShift, 0, 0)
 		if t.Year() == 0 {
 			trace("Year 0 probably means the year was missing")
 			*t = t.AddDate(curryear, 0, 0)
@@ -262,16 +949,21 @@ func guessBadDate(f, i string, d time.Time) []Guess {
 		}
 		fixup(&t)
 		zone, _ := t.Zone()
-		l := fmt.Sprintf("From %s (%s): %s", zone, loc, t.Local())
-		if !wantcal {
-			_, s := deltaNow(t)
+		l := fmt.Sprintf("From %s (%s): %s", zone, loc, formatTime(t.Local()))
+		if _, s := deltaNow(t); !wantcal && s != "" {
 			l += fmt.Sprintf(" (%s)", s)
 		}
+		if note := wallTimeNote(d, loc); note != "" {
+			l += "; " + note
+		}
 		lines = append(lines, l)
 	}
 	ut, _ := time.ParseInLocation(f, i, time.UTC)
 	fixup(&ut)
 	lines = append(lines, fmt.Sprintf("As UNIX timestamp: %d", ut.Unix()))
+	lines = append(lines, sunLines(d)...)
+	lines = append(lines, weekLines(d)...)
+	lines = append(lines, moonLines(d)...)
 
 	good := 0
 	switch {
@@ -288,7 +980,7 @@ func guessBadDate(f, i string, d time.Time) []Guess {
 	}
 
 	return []Guess{{
-		guess:      "In local time: " + d.String(),
+		guess:      "In local time: " + formatTime(d),
 		comment:    ds,
 		additional: additional,
 		goodness:   good,
@@ -301,8 +993,7 @@ type SizeWithUnit struct {
 	val  float64
 }
 
-func guessBytesWithUnit(mult int, val float64) []Guess {
-	n := int(val * float64(mult))
+func guessBytesWithUnit(n int64) []Guess {
 	return []Guess{{
 		guess:      fmt.Sprintf("%d bytes", n),
 		additional: bytesInfo(n),
@@ -310,7 +1001,7 @@ func guessBytesWithUnit(mult int, val float64) []Guess {
 	}}
 }
 
-func guessByteSize(n int) []Guess {
+func guessByteSize(n int64) []Guess {
 	return []Guess{{
 		guess:      fmt.Sprintf("%d bytes", n),
 		additional: bytesInfo(n),
@@ -318,15 +1009,25 @@ func guessByteSize(n int) []Guess {
 	}}
 }
 
-func bytesInfo(n int) []string {
+// guessTooManyBytes is for byte counts that don't fit into an int64.
+func guessTooManyBytes(s string) []Guess {
+	return []Guess{{
+		guess:      s + " bytes",
+		comment:    "value too large to represent exactly",
+		additional: []string{"8 EiB or more"},
+		source:     "byte count",
+	}}
+}
+
+func bytesInfo(n int64) []string {
 	var lines []string
-	for _, u := range byteUnits {
-		p := float64(n) / float64(u.mult)
-		q := float64(n) / float64(u.altMult)
-		if q < 1 {
+	for _, u := range bytesize.Units {
+		p := float64(n) / float64(u.BinaryMult)
+		q := float64(n) / float64(u.DecimalMult)
+		if math.Abs(q) < 1 {
 			continue
 		}
-		lines = append(lines, fmt.Sprintf("%.1f %s (%.1f %s)", p, u.sym, q, u.altSym))
+		lines = append(lines, fmt.Sprintf("%.1f %s (%.1f %s)", p, u.Binary, q, u.Decimal))
 	}
 	trace("bytesInfo: %+v", lines)
 	return lines
@@ -358,21 +1059,65 @@ func guessTimestamp(ts int64) []Guess {
 	return gs
 }
 
+// Layouts from the time package that can be given to -time-format by name.
+var namedLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"DateTime":    time.DateTime,
+}
+
+// formatTime renders t the way the -time-format and -clock flags ask for,
+// by default like time.Time.String() does.
+func formatTime(t time.Time) string {
+	switch {
+	case namedLayouts[*timeFormat] != "":
+		return t.Format(namedLayouts[*timeFormat])
+	case *timeFormat != "":
+		return t.Format(*timeFormat)
+	case *hourClock == 12:
+		return t.Format("2006-01-02 3:04:05.999999999 PM -0700 MST")
+	}
+	return t.Format("2006-01-02 15:04:05.999999999 -0700 MST")
+}
+
+// deltaNow describes how far t is from now, like "2 days 3 hours ago".
+// With -stable, the description is left out.
 func deltaNow(t time.Time) (time.Duration, string) {
+	d, desc := deltaFrom(now(), t)
+	if *stable {
+		return d, ""
+	}
+	return d, desc
+}
+
+// deltaFrom describes how far t is from ref.
+func deltaFrom(ref, t time.Time) (time.Duration, string) {
 	var suff string
 	var d time.Duration
 
-	now := time.Now()
-	if now.Before(t) {
+	if ref.Before(t) {
 		suff = "ahead"
-		d = t.Sub(now)
+		d = t.Sub(ref)
 	} else {
 		suff = "ago"
-		d = now.Sub(t)
+		d = ref.Sub(t)
 	}
 	if d < 1*time.Second {
 		return time.Duration(0), "right now"
 	}
+	if *relativeStyle != "exact" {
+		return d, roundedDelta(d, suff)
+	}
 
 	interv := []struct {
 		d    time.Duration
@@ -416,6 +1161,60 @@ func deltaNow(t time.Time) (time.Duration, string) {
 	return d, roughly + exact
 }
 
+// Units for -relative-style rounded and terse, largest first.
+var roundedUnits = []struct {
+	d           time.Duration
+	name, short string
+}{
+	{365 * 24 * time.Hour, "year", "y"},
+	{30 * 24 * time.Hour, "month", "mo"},
+	{24 * time.Hour, "day", "d"},
+	{time.Hour, "hour", "h"},
+	{time.Minute, "minute", "m"},
+	{time.Second, "second", "s"},
+}
+
+// roundedDelta describes d in its largest unit, like "about 2 days ago" or,
+// with -relative-style terse, "2d ago".
+func roundedDelta(d time.Duration, suff string) string {
+	for i, u := range roundedUnits {
+		if d < u.d {
+			continue
+		}
+		n := int((d + u.d/2) / u.d)
+		if i > 0 && time.Duration(n)*u.d >= roundedUnits[i-1].d-u.d/2 {
+			// Rounded up to about one of the next larger unit, like
+			// 23h50m to 24 hours, which is better said as 1 day.
+			u = roundedUnits[i-1]
+			n = int((d + u.d/2) / u.d)
+		}
+		if *relativeStyle == "terse" {
+			return fmt.Sprintf("%d%s %s", n, u.short, suff)
+		}
+		plural := ""
+		if n > 1 {
+			plural = "s"
+		}
+		about := "about "
+		if d%u.d == 0 {
+			about = ""
+		}
+		return fmt.Sprintf("%s%d %s%s %s", about, n, u.name, plural, suff)
+	}
+	return suff
+}
+
+// weekLines describes where in its year t is for -week, like "Q3, ISO
+// week 2015-W39, day 270 of 365".
+func weekLines(t time.Time) []string {
+	if !*weekInfo {
+		return nil
+	}
+	year, week := t.ISOWeek()
+	days := time.Date(t.Year(), 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+	return []string{fmt.Sprintf("Q%d, ISO week %d-W%02d, day %d of %d", (int(t.Month())-1)/3+1, year, week, t.YearDay(), days)}
+}
+
 func dateGuess(t time.Time) Guess {
 	d, dstr := deltaNow(t)
 	good := -10
@@ -445,24 +1244,84 @@ func dateGuess(t time.Time) Guess {
 	if wanttzs {
 		tzs = []string{"In other time zones:"}
 		tzs = append(tzs, differentTZs(t)...)
+		tzs = append(tzs, dstLines(t)...)
 		tzs = append(tzs, fmt.Sprintf("UNIX timestamp: %d", t.Unix()))
+		tzs = append(tzs, subsecondTimestamp(t)...)
+		tzs = append(tzs, sunLines(t)...)
+		tzs = append(tzs, weekLines(t)...)
+		tzs = append(tzs, moonLines(t)...)
 	}
 	if wantcal || *alwaysCalendar {
 		cal = calendar(t)
 	}
 	additional := sideBySide(tzs, cal)
 	return Guess{
-		guess:      t.String(),
+		guess:      formatTime(t),
 		comment:    dstr,
 		additional: additional,
 		goodness:   good,
 	}
 }
 
+// subsecondTimestamp returns the UNIX timestamp of t in milli-, micro- or
+// nanoseconds, whichever is precise enough, if t has fractional seconds.
+func subsecondTimestamp(t time.Time) []string {
+	ns := t.Nanosecond()
+	switch {
+	case ns == 0:
+		return nil
+	case ns%1e6 == 0:
+		return []string{fmt.Sprintf("UNIX timestamp (milliseconds): %d", t.UnixMilli())}
+	case ns%1e3 == 0:
+		return []string{fmt.Sprintf("UNIX timestamp (microseconds): %d", t.UnixMicro())}
+	}
+	return []string{fmt.Sprintf("UNIX timestamp (nanoseconds): %d", t.UnixNano())}
+}
+
+// differentTZs renders t in each of the -timezones, either in full or, with
+// -format-time-zone-only-offsets, compactly like "11:29 (-07:00) America/Los_Angeles".
+// Zones showing the same time are listed on one line.  With -sort-timezones,
+// zones are listed west to east instead of in the order they were given.
+// With -explain-timezone, each line tells which time the zones are on, see
+// zoneNote.
 func differentTZs(t time.Time) []string {
+	layout := "15:04 (-07:00)"
+	if *hourClock == 12 {
+		layout = "3:04 PM (-07:00)"
+	}
+	locs := TZs
+	if *sortTZs {
+		locs = append([]*time.Location(nil), TZs...)
+		sort.SliceStable(locs, func(i, j int) bool {
+			_, oi := t.In(locs[i]).Zone()
+			_, oj := t.In(locs[j]).Zone()
+			return oi < oj
+		})
+	}
+	type group struct{ time, note string }
+	var groups []group
+	zones := map[group][]string{}
+	for _, loc := range locs {
+		g := group{formatTime(t.In(loc)), zoneNote(t, loc)}
+		if *tzOffsetsOnly {
+			g.time = t.In(loc).Format(layout)
+		}
+		if zones[g] == nil {
+			groups = append(groups, g)
+		}
+		zones[g] = append(zones[g], loc.String())
+	}
 	var lines []string
-	for _, loc := range TZs {
-		lines = append(lines, fmt.Sprintf("%s (%s)", t.In(loc).String(), loc.String()))
+	for _, g := range groups {
+		names := strings.Join(zones[g], ", ")
+		if g.note != "" {
+			names += ": " + g.note
+		}
+		if *tzOffsetsOnly {
+			lines = append(lines, fmt.Sprintf("%s %s", g.time, names))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s (%s)", g.time, names))
 	}
 	return lines
 }
@@ -476,17 +1335,27 @@ func differentTZs(t time.Time) []string {
 //    21 22 23 24 25 26 27
 //    28 29 30
 func calendar(t time.Time) []string {
-	pad := strings.Repeat(" ", (20-(len(t.Month().String())+1+4))/2)
-	caption := cHighlight(fmt.Sprintf("%s%s %d", pad, t.Month(), t.Year()))
+	// Columns are two wide, or wider for longer day abbreviations.
+	w := 2
+	for _, a := range names.abbrevs {
+		w = max(w, displayWidth(a))
+	}
+	month := names.months[t.Month()-1]
+	pad := strings.Repeat(" ", max(0, (7*w+6-(displayWidth(month)+1+4))/2))
+	caption := cHighlight(fmt.Sprintf("%s%s %d", pad, month, t.Year()))
+	var header []string
+	for i := 1; i <= 7; i++ {
+		header = append(header, padRight(names.abbrevs[i%7], w))
+	}
 	lines := []string{
 		caption,
-		"Mo Tu We Th Fr Sa Su",
+		strings.Join(header, " "),
 	}
 
 	dom := t.Day()
-	now := time.Now()
-	today := now.Day()
-	currentmonth := t.Year() == now.Year() && t.Month() == now.Month()
+	ref := now().In(t.Location())
+	today := ref.Day()
+	currentmonth := t.Year() == ref.Year() && t.Month() == ref.Month()
 
 	// First day of the given month
 	i := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
@@ -503,7 +1372,7 @@ func calendar(t time.Time) []string {
 			}
 			if j.Month() != t.Month() {
 				// We are in the previous month, pad with spaces
-				days = append(days, "  ")
+				days = append(days, strings.Repeat(" ", w))
 				continue
 			}
 			if j.Day() != i.Day() && j.Weekday() == time.Monday {
@@ -512,13 +1381,13 @@ func calendar(t time.Time) []string {
 			day := j.Day()
 			switch {
 			case day == dom:
-				days = append(days, cGiven(fmt.Sprintf("%2d", day)))
-			case currentmonth && day == today:
-				days = append(days, cToday(fmt.Sprintf("%2d", day)))
+				days = append(days, cGiven(fmt.Sprintf("%*d", w, day)))
+			case currentmonth && day == today && !*stable:
+				days = append(days, cToday(fmt.Sprintf("%*d", w, day)))
 			case j.Weekday() == time.Sunday:
-				days = append(days, cSunday(fmt.Sprintf("%2d", day)))
+				days = append(days, cSunday(fmt.Sprintf("%*d", w, day)))
 			default:
-				days = append(days, fmt.Sprintf("%2d", day))
+				days = append(days, fmt.Sprintf("%*d", w, day))
 			}
 		}
 		line := strings.Join(days, " ")
@@ -528,34 +1397,49 @@ func calendar(t time.Time) []string {
 }
 
 func guessIP(ip net.IP) []Guess {
-	var additional []string
-	r, err := net.LookupAddr(ip.String())
+	g := ipGuess(ip)
+	if *offline {
+		return []Guess{g}
+	}
+	ctx, cancel := lookupContext()
+	defer cancel()
+	r, err := net.DefaultResolver.LookupAddr(ctx, ip.String())
 	if err != nil {
-		additional = append(additional, "(address does not resolve to a host name)")
+		g.additional = append(g.additional, "(address does not resolve to a host name)")
 	} else {
 		for _, h := range r {
-			additional = append(additional, fmt.Sprintf("reverse lookup: %s", h))
-			addrs, err := net.LookupHost(h)
+			g.additional = append(g.additional, fmt.Sprintf("reverse lookup: %s", h))
+			addrs, err := net.DefaultResolver.LookupHost(ctx, h)
 			if err == nil {
-				additional = append(additional, fmt.Sprintf("which resolves to: %s", strings.Join(addrs, ", ")))
+				g.additional = append(g.additional, fmt.Sprintf("which resolves to: %s", strings.Join(addrs, ", ")))
 			} else {
-				additional = append(additional, "(which does not forward-resolve to anything)")
+				g.additional = append(g.additional, "(which does not forward-resolve to anything)")
 			}
 		}
 	}
-	return []Guess{{
+	return []Guess{g}
+}
+
+// ipGuess describes ip without any network lookups.
+func ipGuess(ip net.IP) Guess {
+	additional := []string{fmt.Sprintf("address class: %s", ipClass(ip))}
+	if v4 := ip.To4(); v4 != nil {
+		n := binary.BigEndian.Uint32(v4)
+		additional = append(additional, fmt.Sprintf("as integer: %d (0x%08x)", n, n))
+	}
+	return Guess{
 		guess:      "IP address " + ip.String(),
 		additional: additional,
 		source:     "IP address",
 		goodness:   200,
-	}}
+	}
 }
 
 func sideBySide(left, right []string) []string {
 	maxlen := 0
 	for _, l := range left {
-		if len(l) > maxlen {
-			maxlen = len(l)
+		if w := displayWidth(l); w > maxlen {
+			maxlen = w
 		}
 	}
 	if maxlen == 0 {
@@ -575,76 +1459,334 @@ func sideBySide(left, right []string) []string {
 		if i < len(left) {
 			l = left[i]
 		}
-		spaces := 4 + maxlen - len(l)
+		spaces := 4 + maxlen - displayWidth(l)
 		out[i] = l + strings.Repeat(" ", spaces) + right[i]
 	}
 	return out
 }
 
+// decodeInput decodes s from the given encoding, which is one of the values
+// accepted by the -decode flag.
+func decodeInput(enc, s string) (string, error) {
+	var b []byte
+	var err error
+	switch strings.ToLower(enc) {
+	case "hex":
+		b, err = hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	case "base64":
+		for _, e := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+			if b, err = e.DecodeString(s); err == nil {
+				break
+			}
+		}
+	case "base32":
+		for _, e := range []*base32.Encoding{base32.StdEncoding, base32.StdEncoding.WithPadding(base32.NoPadding)} {
+			if b, err = e.DecodeString(strings.ToUpper(s)); err == nil {
+				break
+			}
+		}
+	default:
+		return "", fmt.Errorf("unknown encoding %q (want hex, base64 or base32)", enc)
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot decode %q as %s: %v", s, enc, err)
+	}
+	return string(b), nil
+}
+
+// printGuesses prints the likely guesses, or all of them if none is likely.
+// It returns false if there was nothing to print.
+func printGuesses(w io.Writer, guesses []Guess) bool {
+	if guesses == nil {
+		fmt.Fprintln(w, "Could not guess anything.")
+		return false
+	}
+	n := 0
+	for _, g := range guesses {
+		if *printUnlikely || g.goodness >= 0 {
+			n++
+			fmt.Fprint(w, g.String())
+		}
+	}
+	if !*printUnlikely && n == 0 {
+		fmt.Fprintln(w, "No good guesses found. How about these unlikely ones?")
+		for _, g := range guesses {
+			fmt.Fprint(w, g.String())
+		}
+	}
+	return true
+}
+
+// hasLikely returns whether any of guesses is likely.
+func hasLikely(guesses []Guess) bool {
+	for _, g := range guesses {
+		if g.goodness >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// loadTimezones loads the comma-separated list of time zones given with the
+// -timezones flag.
+func loadTimezones(spec string) ([]*time.Location, error) {
+	var locs []*time.Location
+	if spec == "" {
+		return nil, nil
+	}
+	for _, tz := range strings.Split(spec, ",") {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, err
+		}
+		locs = append(locs, loc)
+	}
+	return locs, nil
+}
+
+func plainColors() {
+	plain := func(a ...interface{}) string { return fmt.Sprint(a...) }
+	cHighlight, cToday, cGiven, cSunday = plain, plain, plain, plain
+}
+
+func pangoColors() {
+	cHighlight = func(a ...interface{}) string {
+		return "<span font_weight='bold'>" + fmt.Sprint(a...) + "</span>"
+	}
+	cToday = func(a ...interface{}) string {
+		return "<span font_weight='bold' bgcolor='#c0c0c0' underline='single'>" + fmt.Sprint(a...) + "</span>"
+	}
+	cGiven = func(a ...interface{}) string {
+		return "<span font_weight='bold' bgcolor='#EB3636'>" + fmt.Sprint(a...) + "</span>"
+	}
+	cSunday = func(a ...interface{}) string { return "<span color='grey'>" + fmt.Sprint(a...) + "</span>" }
+}
+
+func ansiColors() {
+	cHighlight = color.New(color.Bold).SprintFunc()
+	cToday = color.New(color.Bold).Add(color.Underline).SprintFunc()
+	cGiven = color.New(color.BgRed).Add(color.Bold).SprintFunc()
+	cSunday = color.New(color.FgMagenta).SprintFunc()
+}
+
 func usage() {
 	fmt.Printf("Usage: %s <string-to-guess>\n", os.Args[0])
+	for _, u := range commandUsage() {
+		fmt.Printf("       %s %s\n", os.Args[0], u)
+	}
 }
 
 func main() {
-	Trace = log.New(os.Stderr, "TRACE: ", log.LstdFlags)
-
 	flag.Parse()
 
-	if *timezones != "" {
-		for _, tz := range strings.Split(*timezones, ",") {
-			loc, err := time.LoadLocation(tz)
-			if err != nil {
-				log.Fatalf("Cannot find time zone: %s", err)
-			}
-			TZs = append(TZs, loc)
+	var err error
+	Trace, err = newTraceLogger(os.Stderr, *traceFormat)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *showVersion {
+		fmt.Print(versionString(debug.ReadBuildInfo()))
+		os.Exit(0)
+	}
+
+	if *completion != "" {
+		if err := writeCompletion(os.Stdout, *completion, filepath.Base(os.Args[0])); err != nil {
+			log.Fatal(err)
 		}
+		os.Exit(0)
+	}
+
+	TZs, err = loadTimezones(*timezones)
+	if err != nil {
+		log.Fatalf("Cannot find time zone: %s", err)
 	}
 
-	if *pangoMarkup {
-		cHighlight = func(a ...interface{}) string {
-			return "<span font_weight='bold'>" + fmt.Sprint(a...) + "</span>"
+	setLocale(*locale)
+	if *hourClock != 12 && *hourClock != 24 {
+		log.Fatalf("Invalid -clock %d, must be 12 or 24", *hourClock)
+	}
+	switch *relativeStyle {
+	case "exact", "rounded", "terse":
+	default:
+		log.Fatalf("Invalid -relative-style %q, must be exact, rounded or terse", *relativeStyle)
+	}
+	switch *snowflakeEpoch {
+	case "", "twitter", "discord":
+	default:
+		log.Fatalf("Invalid -snowflake-epoch %q, must be twitter or discord", *snowflakeEpoch)
+	}
+	if *prefer != "" {
+		if err := checkGuesserName(*prefer); err != nil {
+			log.Fatalf("Invalid -prefer: %s", err)
 		}
-		cToday = func(a ...interface{}) string {
-			return "<span font_weight='bold' bgcolor='#c0c0c0' underline='single'>" + fmt.Sprint(a...) + "</span>"
+	}
+	for name := range onlyGuessers() {
+		if err := checkGuesserName(name); err != nil {
+			log.Fatalf("Invalid -only: %s", err)
 		}
-		cGiven = func(a ...interface{}) string {
-			return "<span font_weight='bold' bgcolor='#EB3636'>" + fmt.Sprint(a...) + "</span>"
+	}
+	if *literal && (*decodeAs != "" || *fieldPath != "") {
+		log.Fatal("-literal cannot be combined with -decode or -field, which change the input")
+	}
+
+	switch {
+	case *jsonOutput || *jsonStream || *tableOutput || *markdown:
+		plainColors()
+	case *pangoMarkup:
+		pangoColors()
+	case *outputFile != "":
+		plainColors()
+	default:
+		ansiColors()
+	}
+
+	out := os.Stdout
+	if *outputFile != "" {
+		out, err = os.Create(*outputFile)
+		if err != nil {
+			log.Fatal(err)
 		}
-		cSunday = func(a ...interface{}) string { return "<span color='grey'>" + fmt.Sprint(a...) + "</span>" }
-	} else {
-		cHighlight = color.New(color.Bold).SprintFunc()
-		cToday = color.New(color.Bold).Add(color.Underline).SprintFunc()
-		cGiven = color.New(color.BgRed).Add(color.Bold).SprintFunc()
-		cSunday = color.New(color.FgMagenta).SprintFunc()
+	} else if *usePager && isatty.IsTerminal(os.Stdout.Fd()) {
+		out, waitPager, err = startPager()
+		if err != nil {
+			log.Fatalf("Cannot start pager: %s", err)
+		}
+	}
+
+	if *sunAt != "" {
+		observerLat, observerLon, err = parseCoordinates(*sunAt)
+		if err != nil {
+			log.Fatal(err)
+		}
+		observerSet = true
 	}
 
-	input := strings.TrimSpace(flag.Arg(0))
+	if *anchorFlag != "" {
+		anchor, err = parseAnchor(*anchorFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		trace("Comparing dates against %s", anchor)
+	}
+
+	if ok, err := runCommand(out, flag.Args()); ok {
+		if err != nil {
+			log.Fatal(err)
+		}
+		finish(out, true)
+	}
+
+	if *diffMode {
+		if flag.NArg() != 2 {
+			log.Fatal("-diff needs two arguments")
+		}
+		ok := printDiff(out, strings.TrimSpace(flag.Arg(0)), strings.TrimSpace(flag.Arg(1)))
+		finish(out, ok)
+	}
+
+	trim := strings.TrimSpace
+	if *literal {
+		trim = func(s string) string { return s }
+	}
+	input := trim(flag.Arg(0))
+	if *fromClipboard {
+		clip, err := readClipboard()
+		if err != nil {
+			log.Fatal(err)
+		}
+		input = trim(clip)
+		if input == "" {
+			log.Fatal("The clipboard is empty")
+		}
+	}
 	if input == "" {
 		usage()
 		os.Exit(-1)
 	}
-	trace("Trying to guess %q", input)
-	guesses := guess(input)
-	if guesses == nil {
-		fmt.Println("Could not guess anything.")
-		os.Exit(-1)
+	if *decodeAs != "" {
+		decoded, err := decodeInput(*decodeAs, input)
+		if err != nil {
+			log.Fatal(err)
+		}
+		trace("Decoded %q from %s as %q", input, *decodeAs, decoded)
+		input = strings.TrimSpace(decoded)
+	}
+	if *fieldPath != "" {
+		value, err := extractField(input, *fieldPath)
+		if err != nil {
+			log.Fatalf("Cannot select -field %s: %s", *fieldPath, err)
+		}
+		trace("Selected %q from %s", value, *fieldPath)
+		input = strings.TrimSpace(value)
 	}
+	traceAttrs(fmt.Sprintf("Trying to guess %q", input), "trying to guess", "input", input)
+	guesses, attempts := tryGuessers(input)
+	adjustGoodness(guesses)
 	if *sortGuesses {
 		sort.Sort(ByGoodness(guesses))
 	}
-	n := 0
-	for _, g := range guesses {
-		if *printUnlikely || g.goodness >= 0 {
-			n++
-			fmt.Print(g.String())
-		}
+	if *goodnessCap {
+		normalizeGoodness(guesses)
 	}
-	if !*printUnlikely && n == 0 {
-		fmt.Println("No good guesses found. How about these unlikely ones?")
+	ok := true
+	if *jsonOutput || *jsonStream {
+		var likely []Guess
 		for _, g := range guesses {
-			fmt.Print(g.String())
+			if *printUnlikely || g.goodness >= 0 {
+				likely = append(likely, g)
+			}
+		}
+		if likely == nil {
+			likely = guesses
+		}
+		write := writeJSON
+		if *jsonStream {
+			write = writeJSONStream
+		}
+		if err := write(out, input, likely); err != nil {
+			log.Fatal(err)
+		}
+		ok = guesses != nil
+	} else if *tableOutput {
+		ok = writeTable(out, guesses)
+	} else if *markdown {
+		ok = writeMarkdown(out, guesses)
+	} else {
+		ok = printGuesses(out, guesses)
+		// -only asks for nothing but the named guessers.
+		if !hasLikely(guesses) && *only == "" {
+			for _, s := range suggest(input) {
+				fmt.Fprintln(out, s)
+			}
 		}
 	}
+	if *verbose {
+		fmt.Fprint(os.Stderr, summarizeAttempts(attempts))
+	}
+	finish(out, ok)
+}
+
+// waitPager waits for the -pager to exit, if one was started.
+var waitPager func() error
+
+// finish closes the -output file or the pipe to the -pager and exits, with
+// an error status unless ok.
+func finish(out *os.File, ok bool) {
+	if out != os.Stdout {
+		if err := out.Close(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if waitPager != nil {
+		if err := waitPager(); err != nil {
+			trace("pager: %v", err)
+		}
+	}
+	if !ok {
+		os.Exit(-1)
+	}
+	os.Exit(0)
 }
 
 // vim:set noet sw=8 ts=8:
@@ -0,0 +1,843 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden files in testdata/")
+
+// testNow is the fixed current time all tests run at: the moment the
+// README examples were made.
+var testNow = time.Date(2015, 9, 27, 9, 28, 42, 85000000, time.UTC)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	clock = func() time.Time { return testNow }
+	time.Local = time.UTC
+	*offline = true
+	*pluginDir = ""
+	color.NoColor = true
+	plainColors()
+	var err error
+	TZs, err = loadTimezones(*timezones)
+	if err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// setFlag sets a flag for the duration of the test.
+func setFlag(t *testing.T, name, value string) {
+	t.Helper()
+	old := flag.Lookup(name).Value.String()
+	if err := flag.Set(name, value); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { flag.Set(name, old) })
+}
+
+func TestGolden(t *testing.T) {
+	for _, tc := range []struct {
+		name, input string
+	}{
+		{"timestamp-seconds", "1443346122"},
+		{"timestamp-milliseconds", "1443346122085"},
+		{"now", "now"},
+		{"bytes-with-unit", "8TiB"},
+		{"date-without-timezone", "2015-09-25 15:00:00"},
+		{"date-with-abbreviation", "2015-09-26 11:29:43 PDT"},
+		{"date-rfc3339", "2015-09-26T11:29:43Z"},
+		{"ip-address", "127.0.0.1"},
+		{"email", "Joe <joe@[192.168.0.1]>"},
+		{"git-sha", "3390ae5"},
+		{"k8s-cpu", "1500m"},
+		{"ansi", `\x1b[1;31m`},
+		{"nothing", "xyzzy"},
+		{"garbage", "#~#"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gs := guess(tc.input)
+			sort.Sort(ByGoodness(gs))
+			var buf bytes.Buffer
+			printGuesses(&buf, gs)
+
+			golden := filepath.Join("testdata", tc.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if got := buf.String(); got != string(want) {
+				t.Errorf("output for %q differs from %s:\n%s", tc.input, golden, got)
+			}
+		})
+	}
+}
+
+func TestDeltaNow(t *testing.T) {
+	for _, tc := range []struct {
+		t    time.Time
+		d    time.Duration
+		desc string
+	}{
+		{testNow, 0, "right now"},
+		{testNow.Add(-30 * time.Second), 30 * time.Second, "within the minute, 30 seconds ago"},
+		{testNow.Add(90 * time.Minute), 90 * time.Minute, "within the day, 1 hour 30 minutes ahead"},
+		{testNow.Add(-50 * time.Hour), 50 * time.Hour, "within the week, 2 days 2 hours ago"},
+		{testNow.AddDate(0, 0, 10), 240 * time.Hour, "10 days ahead"},
+	} {
+		d, desc := deltaNow(tc.t)
+		if d != tc.d || desc != tc.desc {
+			t.Errorf("deltaNow(%v) = %v, %q, want %v, %q", tc.t, d, desc, tc.d, tc.desc)
+		}
+	}
+}
+
+func TestDeltaNowAnchor(t *testing.T) {
+	var err error
+	anchor, err = parseAnchor("2015-01-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { anchor = time.Time{} }()
+	if _, desc := deltaNow(time.Date(2015, 1, 2, 0, 0, 0, 0, time.UTC)); desc != "within the week, 1 day ahead" {
+		t.Errorf("deltaNow() relative to anchor = %q, want %q", desc, "within the week, 1 day ahead")
+	}
+}
+
+func TestDeltaNowRelativeStyle(t *testing.T) {
+	for _, tc := range []struct {
+		d              time.Duration
+		rounded, terse string
+	}{
+		{-51*time.Hour - 7*time.Minute, "about 2 days ago", "2d ago"},
+		{2 * time.Hour, "2 hours ahead", "2h ahead"},
+		{90 * time.Second, "about 2 minutes ahead", "2m ahead"},
+		{-40 * 24 * time.Hour, "about 1 month ago", "1mo ago"},
+		{-3 * 365 * 24 * time.Hour, "3 years ago", "3y ago"},
+		{5 * time.Second, "5 seconds ahead", "5s ahead"},
+		{-23*time.Hour - 50*time.Minute, "about 1 day ago", "1d ago"},
+		{59*time.Second + 600*time.Millisecond, "about 1 minute ahead", "1m ahead"},
+		{59*time.Minute + 45*time.Second, "about 1 hour ahead", "1h ahead"},
+		{-359 * 24 * time.Hour, "about 1 year ago", "1y ago"},
+		{-29*24*time.Hour - 20*time.Hour, "about 1 month ago", "1mo ago"},
+		{-23 * time.Hour, "23 hours ago", "23h ago"},
+	} {
+		setFlag(t, "relative-style", "rounded")
+		if _, got := deltaNow(testNow.Add(tc.d)); got != tc.rounded {
+			t.Errorf("deltaNow(now%+v) rounded = %q, want %q", tc.d, got, tc.rounded)
+		}
+		setFlag(t, "relative-style", "terse")
+		if _, got := deltaNow(testNow.Add(tc.d)); got != tc.terse {
+			t.Errorf("deltaNow(now%+v) terse = %q, want %q", tc.d, got, tc.terse)
+		}
+	}
+}
+
+func TestParseAnchor(t *testing.T) {
+	for _, s := range []string{"1443346122", "2015-09-27 09:28:42 UTC", "2015-09-27T09:28:42Z", "2015-09-27 09:28:42"} {
+		a, err := parseAnchor(s)
+		if err != nil {
+			t.Errorf("parseAnchor(%q): %v", s, err)
+			continue
+		}
+		if want := time.Unix(1443346122, 0); !a.Equal(want) {
+			t.Errorf("parseAnchor(%q) = %v, want %v", s, a, want)
+		}
+	}
+	if _, err := parseAnchor("Jan 2"); err == nil {
+		t.Errorf("parseAnchor() accepted a date without year")
+	}
+}
+
+func TestDateGuessGoodness(t *testing.T) {
+	for _, tc := range []struct {
+		d    time.Duration
+		good int
+	}{
+		{10 * time.Second, 200},
+		{10 * time.Minute, 180},
+		{10 * time.Hour, 150},
+		{3 * 24 * time.Hour, 120},
+		{100 * 24 * time.Hour, 20},
+		{3 * 365 * 24 * time.Hour, 0},
+		{30 * 365 * 24 * time.Hour, -10},
+	} {
+		if g := dateGuess(testNow.Add(-tc.d)); g.goodness != tc.good {
+			t.Errorf("goodness of a date %v ago = %d, want %d", tc.d, g.goodness, tc.good)
+		}
+	}
+}
+
+func TestGuessTimestamp(t *testing.T) {
+	gs := guessTimestamp(1443346122)
+	var sources []string
+	for _, g := range gs {
+		sources = append(sources, g.source)
+	}
+	want := []string{"timestamp (seconds)", "timestamp (milliseconds)", "timestamp (microseconds)", "timestamp (nanoseconds)"}
+	if !reflect.DeepEqual(sources, want) {
+		t.Errorf("sources = %v, want %v", sources, want)
+	}
+	if g := gs[0]; g.guess != "Timestamp 1443346122 is 2015-09-27 09:28:42 +0000 UTC" || g.goodness != 200 {
+		t.Errorf("guessTimestamp(1443346122)[0] = %+v", g)
+	}
+	if gs := guessTimestamp(0); gs != nil {
+		t.Errorf("guessTimestamp(0) = %+v, want nil", gs)
+	}
+}
+
+func TestGuessBytes(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"1024", "1024 bytes"},
+		{"8TiB", "8796093022208 bytes"},
+		{"1.5KiB", "1536 bytes"},
+		{"2 MB", "2000000 bytes"},
+		{"3G", "3221225472 bytes"},
+		{"2.5PiB", "2814749767106560 bytes"},
+		{"0.1GiB", "107374182 bytes"},
+		{"-5MiB", "-5242880 bytes"},
+		{"-1.5 KB", "-1500 bytes"},
+		{"-2048", "-2048 bytes"},
+		{"7EiB", "8070450532247928832 bytes"},
+	} {
+		gs := guessBytes(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.want {
+			t.Errorf("guessBytes(%q) = %+v, want %q", tc.in, gs, tc.want)
+		}
+	}
+	if gs := guessBytes("KiB"); gs != nil {
+		t.Errorf("guessBytes(%q) = %+v, want nil", "KiB", gs)
+	}
+	for _, s := range []string{"8EiB", "9999PB", "99999999999999999999"} {
+		gs := guessBytes(s)
+		if len(gs) != 1 || gs[0].comment != "value too large to represent exactly" {
+			t.Errorf("guessBytes(%q) = %+v, want too large", s, gs)
+		}
+	}
+}
+
+func TestParseInt(t *testing.T) {
+	for _, tc := range []struct {
+		in, sep string
+		want    int64
+	}{
+		{"1234567", ".", 1234567},
+		{"1,234,567", ".", 1234567},
+		{"-1,234", ".", -1234},
+		{"1_000_000", ".", 1000000},
+		{"1 234 567", ".", 1234567},
+		{"1\u202f234", ".", 1234},
+		{"1'234'567", ".", 1234567},
+		{"1.234.567", ",", 1234567},
+		{"1.234", ",", 1234},
+	} {
+		setFlag(t, "decimal-separator", tc.sep)
+		if got, err := parseInt(tc.in); err != nil || got != tc.want {
+			t.Errorf("parseInt(%q) with -decimal-separator %s = %d, %v, want %d", tc.in, tc.sep, got, err, tc.want)
+		}
+	}
+	setFlag(t, "decimal-separator", ".")
+	for _, s := range []string{"1,23", "1234,567", ",123", "1,234.5", "1__0", "_1", "0x_ff", "1.234", "1,234.567"} {
+		if got, err := parseInt(s); err == nil {
+			t.Errorf("parseInt(%q) = %d, want error", s, got)
+		}
+	}
+	setFlag(t, "decimal-separator", ",")
+	if got, err := parseInt("1,234"); err == nil {
+		t.Errorf("parseInt(1,234) with -decimal-separator , = %d, want error", got)
+	}
+
+	setFlag(t, "decimal-separator", ".")
+	setFlag(t, "literal", "true")
+	for _, s := range []string{"1,234,567", "1_000_000", "1 234"} {
+		if got, err := parseInt(s); err == nil {
+			t.Errorf("parseInt(%q) with -literal = %d, want error", s, got)
+		}
+	}
+	if got, err := parseInt("-1234"); err != nil || got != -1234 {
+		t.Errorf("parseInt(-1234) with -literal = %d, %v", got, err)
+	}
+}
+
+func TestBytesInfo(t *testing.T) {
+	got := bytesInfo(1536)
+	want := []string{"1.5 KiB (1.5 KB)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bytesInfo(1536) = %q, want %q", got, want)
+	}
+	got = bytesInfo(-5242880)
+	want = []string{"-5120.0 KiB (-5242.9 KB)", "-5.0 MiB (-5.2 MB)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bytesInfo(-5242880) = %q, want %q", got, want)
+	}
+}
+
+func TestGuessDateResolvesAbbreviation(t *testing.T) {
+	gs := guessDate("2015-09-26 11:29:43 PDT")
+	if len(gs) != 1 {
+		t.Fatalf("guessDate() = %+v, want one guess", gs)
+	}
+	if want := "2015-09-26 11:29:43 -0700 PDT"; gs[0].guess != want {
+		t.Errorf("guessDate() = %q, want %q", gs[0].guess, want)
+	}
+}
+
+func TestCustomDateFormats(t *testing.T) {
+	defer func() { customFormats = nil }()
+	for _, layout := range []string{"no reference", "12006"} {
+		if err := addDateFormat(layout); err == nil {
+			t.Errorf("addDateFormat(%q) succeeded", layout)
+		}
+	}
+	if err := addDateFormat("02.01.2006 15h04"); err != nil {
+		t.Fatal(err)
+	}
+	gs := guessDate("26.09.2015 23h29")
+	if len(gs) != 1 || gs[0].source != `date string with custom format "02.01.2006 15h04"` {
+		t.Fatalf("guessDate() = %+v, want one guess from the custom format", gs)
+	}
+	if want := "2015-09-26 23:29:00 +0000 UTC"; !strings.HasPrefix(gs[0].guess, want) {
+		t.Errorf("guessDate() = %q, want %q", gs[0].guess, want)
+	}
+}
+
+func TestTwoDigitYears(t *testing.T) {
+	for _, tc := range []struct {
+		in, pivot string
+		years     []string
+	}{
+		{"12/31/99", "69", []string{"1999"}},
+		{"12/31/63", "69", []string{"2063"}},
+		{"12/31/64", "69", []string{"2064", "1964"}},
+		{"12/31/68", "69", []string{"2068", "1968"}},
+		{"12/31/69", "69", []string{"1969", "2069"}},
+		{"12/31/73", "69", []string{"1973", "2073"}},
+		{"12/31/74", "69", []string{"1974"}},
+		{"31.12.44", "50", []string{"2044"}},
+		{"31.12.45", "50", []string{"2045", "1945"}},
+		{"31.12.49", "50", []string{"2049", "1949"}},
+		{"31.12.54", "50", []string{"1954", "2054"}},
+		{"31.12.55", "50", []string{"1955"}},
+		{"31-Dec-30", "50", []string{"2030"}},
+	} {
+		setFlag(t, "year-pivot", tc.pivot)
+		gs := guessDate(tc.in)
+		var years []string
+		for _, g := range gs {
+			years = append(years, strings.TrimPrefix(g.guess, "In local time: ")[:4])
+		}
+		if !reflect.DeepEqual(years, tc.years) {
+			t.Errorf("guessDate(%q) with -year-pivot %s = %+v, want years %q", tc.in, tc.pivot, gs, tc.years)
+		}
+	}
+	setFlag(t, "year-pivot", "69")
+	gs := guessDate("12/31/99")
+	if want := "two-digit year 99 taken as 1999 (-year-pivot 69)"; len(gs) != 1 || gs[0].additional[0] != want {
+		t.Errorf("guessDate(12/31/99) = %+v, want note %q", gs, want)
+	}
+}
+
+func TestGuessBadDate(t *testing.T) {
+	gs := guessDate("Sep 25")
+	if len(gs) == 0 {
+		t.Fatal("guessDate(\"Sep 25\") found nothing")
+	}
+	g := gs[0]
+	if g.source != "date string without timezone" {
+		t.Errorf("source = %q", g.source)
+	}
+	if want := "In local time: 2015-09-25 00:00:00 +0000 UTC"; g.guess != want {
+		t.Errorf("guess = %q, want %q (year should default to the current one)", g.guess, want)
+	}
+	if g.goodness != 50 {
+		t.Errorf("goodness = %d, want 50", g.goodness)
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	got := calendar(time.Date(2015, 9, 25, 0, 0, 0, 0, time.UTC))
+	want := []string{
+		"   September 2015",
+		"Mo Tu We Th Fr Sa Su",
+		"    1  2  3  4  5  6",
+		" 7  8  9 10 11 12 13",
+		"14 15 16 17 18 19 20",
+		"21 22 23 24 25 26 27",
+		"28 29 30",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("calendar() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSideBySide(t *testing.T) {
+	got := sideBySide([]string{"a", "bbb"}, []string{"1", "2", "3"})
+	want := []string{"a      1", "bbb    2", "       3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sideBySide() = %q, want %q", got, want)
+	}
+	if got := sideBySide(nil, []string{"x"}); !reflect.DeepEqual(got, []string{"x"}) {
+		t.Errorf("sideBySide(nil, ...) = %q", got)
+	}
+}
+
+func TestDecodeInput(t *testing.T) {
+	for _, tc := range []struct {
+		enc, in, want string
+	}{
+		{"hex", "3132372e302e302e31", "127.0.0.1"},
+		{"hex", "0x6869", "hi"},
+		{"base64", "MTI3LjAuMC4x", "127.0.0.1"},
+		{"base64", "aGk", "hi"},
+		{"base32", "NBUQ====", "hi"},
+		{"base32", "nbuq", "hi"},
+	} {
+		got, err := decodeInput(tc.enc, tc.in)
+		if err != nil || got != tc.want {
+			t.Errorf("decodeInput(%q, %q) = %q, %v, want %q", tc.enc, tc.in, got, err, tc.want)
+		}
+	}
+	if _, err := decodeInput("base64", "!!"); err == nil {
+		t.Error("decodeInput() accepted invalid base64")
+	}
+	if _, err := decodeInput("rot13", "x"); err == nil {
+		t.Error("decodeInput() accepted an unknown encoding")
+	}
+}
+
+func TestVerboseString(t *testing.T) {
+	setFlag(t, "verbose", "true")
+	g := Guess{guess: "g", comment: "c", additional: []string{"a"}, source: "s", goodness: 7}
+	if got, want := g.String(), "[goodness: 7, source: s]\ng (c)\n    a\n"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestNormalizeGoodness(t *testing.T) {
+	gs := []Guess{{goodness: 200}, {goodness: 50}, {goodness: -10}}
+	normalizeGoodness(gs)
+	for i, want := range []int{100, 28, 0} {
+		if gs[i].percent != want {
+			t.Errorf("normalizeGoodness() percent[%d] = %d, want %d", i, gs[i].percent, want)
+		}
+	}
+	gs = []Guess{{goodness: 7}}
+	normalizeGoodness(gs)
+	if gs[0].percent != 100 {
+		t.Errorf("normalizeGoodness() single guess percent = %d, want 100", gs[0].percent)
+	}
+	setFlag(t, "verbose", "true")
+	setFlag(t, "max-goodness-cap", "true")
+	g := Guess{guess: "g", source: "s", goodness: 7, percent: 100}
+	if got, want := g.String(), "[goodness: 7 (100%), source: s]\ng\n"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestSubsecondTimestamp(t *testing.T) {
+	for _, tc := range []struct {
+		ns   int
+		want []string
+	}{
+		{0, nil},
+		{85000000, []string{"UNIX timestamp (milliseconds): 1443346122085"}},
+		{85001000, []string{"UNIX timestamp (microseconds): 1443346122085001"}},
+		{85000001, []string{"UNIX timestamp (nanoseconds): 1443346122085000001"}},
+	} {
+		at := time.Unix(1443346122, int64(tc.ns))
+		if got := subsecondTimestamp(at); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("subsecondTimestamp(%v) = %q, want %q", at, got, tc.want)
+		}
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	at := time.Date(2015, 9, 26, 23, 29, 43, 0, time.UTC)
+	for _, tc := range []struct {
+		clock, format, want string
+	}{
+		{"24", "", "2015-09-26 23:29:43 +0000 UTC"},
+		{"12", "", "2015-09-26 11:29:43 PM +0000 UTC"},
+		{"12", "15:04", "23:29"},
+		{"24", "Kitchen", "11:29PM"},
+	} {
+		setFlag(t, "clock", tc.clock)
+		setFlag(t, "time-format", tc.format)
+		if got := formatTime(at); got != tc.want {
+			t.Errorf("formatTime() with -clock %s -time-format %q = %q, want %q", tc.clock, tc.format, got, tc.want)
+		}
+	}
+}
+
+func TestDifferentTZsOffsetsOnly(t *testing.T) {
+	setFlag(t, "format-time-zone-only-offsets", "true")
+	at := time.Date(2015, 9, 26, 23, 29, 43, 0, time.UTC)
+	want := []string{
+		"16:29 (-07:00) America/Los_Angeles",
+		"19:29 (-04:00) America/New_York",
+		"23:29 (+00:00) UTC",
+		"01:29 (+02:00) Europe/Berlin",
+		"03:29 (+04:00) Asia/Dubai",
+		"07:29 (+08:00) Asia/Singapore",
+		"09:29 (+10:00) Australia/Sydney",
+	}
+	if got := differentTZs(at); !reflect.DeepEqual(got, want) {
+		t.Errorf("differentTZs() = %q, want %q", got, want)
+	}
+	setFlag(t, "clock", "12")
+	if got := differentTZs(at)[0]; got != "4:29 PM (-07:00) America/Los_Angeles" {
+		t.Errorf("differentTZs() with -clock 12 = %q", got)
+	}
+}
+
+func TestDifferentTZsGroupsSameTime(t *testing.T) {
+	old := TZs
+	defer func() { TZs = old }()
+	var err error
+	TZs, err = loadTimezones("Europe/Berlin,Europe/London,Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2015, 9, 26, 23, 29, 43, 0, time.UTC)
+	want := []string{
+		"2015-09-27 01:29:43 +0200 CEST (Europe/Berlin, Europe/Paris)",
+		"2015-09-27 00:29:43 +0100 BST (Europe/London)",
+	}
+	if got := differentTZs(at); !reflect.DeepEqual(got, want) {
+		t.Errorf("differentTZs() = %q, want %q", got, want)
+	}
+	setFlag(t, "format-time-zone-only-offsets", "true")
+	want = []string{
+		"01:29 (+02:00) Europe/Berlin, Europe/Paris",
+		"00:29 (+01:00) Europe/London",
+	}
+	if got := differentTZs(at); !reflect.DeepEqual(got, want) {
+		t.Errorf("differentTZs() = %q, want %q", got, want)
+	}
+}
+
+func TestDifferentTZsSorted(t *testing.T) {
+	old := TZs
+	defer func() { TZs = old }()
+	var err error
+	TZs, err = loadTimezones("Asia/Dubai,America/New_York,UTC,Asia/Kolkata,America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	setFlag(t, "format-time-zone-only-offsets", "true")
+	setFlag(t, "sort-timezones", "true")
+	at := time.Date(2015, 9, 26, 23, 29, 43, 0, time.UTC)
+	want := []string{
+		"16:29 (-07:00) America/Los_Angeles",
+		"19:29 (-04:00) America/New_York",
+		"23:29 (+00:00) UTC",
+		"03:29 (+04:00) Asia/Dubai",
+		"04:59 (+05:30) Asia/Kolkata",
+	}
+	if got := differentTZs(at); !reflect.DeepEqual(got, want) {
+		t.Errorf("differentTZs() = %q, want %q", got, want)
+	}
+}
+
+func TestPrefer(t *testing.T) {
+	setFlag(t, "prefer", "bytes")
+	gs := guess("1443346122")
+	sort.Sort(ByGoodness(gs))
+	if gs[0].source != "byte count without explicit unit" {
+		t.Errorf("guess() with -prefer bytes ranks %q first", gs[0].source)
+	}
+	if len(gs) < 2 {
+		t.Errorf("guess() with -prefer bytes hides other guesses: %+v", gs)
+	}
+	if err := checkGuesserName("timestamp"); err != nil {
+		t.Error(err)
+	}
+	if err := checkGuesserName("nonsense"); err == nil {
+		t.Error("checkGuesserName(nonsense) succeeded")
+	}
+}
+
+func TestGuessTimestampWithUnit(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess, source string
+		goodness          int
+	}{
+		{"1443346122s", "Timestamp 1443346122s is 2015-09-27 09:28:42 +0000 UTC", "timestamp (seconds) with explicit unit", 200},
+		{"1443346122085ms", "Timestamp 1443346122085ms is 2015-09-27 09:28:42.085 +0000 UTC", "timestamp (milliseconds) with explicit unit", 200},
+		{"1443346122085000us", "Timestamp 1443346122085000us is 2015-09-27 09:28:42.085 +0000 UTC", "timestamp (microseconds) with explicit unit", 200},
+		{"1443346122085000000ns", "Timestamp 1443346122085000000ns is 2015-09-27 09:28:42.085 +0000 UTC", "timestamp (nanoseconds) with explicit unit", 200},
+		{"30s", "Timestamp 30s is 1970-01-01 00:00:30 +0000 UTC", "timestamp (seconds) with explicit unit", -10},
+	} {
+		gs := guessTimestampString(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess || gs[0].source != tc.source || gs[0].goodness != tc.goodness {
+			t.Errorf("guessTimestampString(%q) = %+v, want %q from %s with goodness %d", tc.in, gs, tc.guess, tc.source, tc.goodness)
+		}
+	}
+	for _, s := range []string{"s", "-5s", "1.5s", "9223372036854775807ms", "5h"} {
+		if gs := guessTimestampString(s); gs != nil {
+			t.Errorf("guessTimestampString(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestWeekLines(t *testing.T) {
+	if got := weekLines(testNow); got != nil {
+		t.Errorf("weekLines() without -week = %q", got)
+	}
+	setFlag(t, "week", "true")
+	for _, tc := range []struct {
+		t    time.Time
+		want string
+	}{
+		{testNow, "Q3, ISO week 2015-W39, day 270 of 365"},
+		{time.Date(2015, 3, 31, 23, 59, 0, 0, time.UTC), "Q1, ISO week 2015-W14, day 90 of 365"},
+		{time.Date(2015, 4, 1, 0, 0, 0, 0, time.UTC), "Q2, ISO week 2015-W14, day 91 of 365"},
+		{time.Date(2015, 12, 31, 0, 0, 0, 0, time.UTC), "Q4, ISO week 2015-W53, day 365 of 365"},
+		{time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), "Q1, ISO week 2015-W53, day 1 of 366"},
+		{time.Date(2016, 12, 31, 0, 0, 0, 0, time.UTC), "Q4, ISO week 2016-W52, day 366 of 366"},
+	} {
+		if got := weekLines(tc.t); len(got) != 1 || got[0] != tc.want {
+			t.Errorf("weekLines(%v) = %q, want %q", tc.t, got, tc.want)
+		}
+	}
+	if gs := guessEpochDay("16705"); len(gs) != 1 || !strings.Contains(strings.Join(gs[0].additional, "\n"), "Q3, ISO week 2015-W39") {
+		t.Errorf("guessEpochDay() with -week = %+v", gs)
+	}
+}
+
+func TestStable(t *testing.T) {
+	setFlag(t, "stable", "true")
+	cToday = func(a ...interface{}) string { return "[" + a[0].(string) + "]" }
+	defer plainColors()
+	output := func(in string) string {
+		var b strings.Builder
+		for _, g := range guess(in) {
+			b.WriteString(g.String())
+		}
+		return b.String()
+	}
+	for _, in := range []string{"1443346122", "2015-09-20 15:00:00", "14:30", "14:30Z", "1000 years ago", "in 2 hours", "+3d", "yesterday", "now"} {
+		s := output(in)
+		if !strings.Contains(in, " ago") && strings.Contains(s, " ago") || strings.Contains(s, " ahead") || strings.Contains(s, "()") || strings.Contains(s, "[27]") {
+			t.Errorf("guess(%q) with -stable printed relative times:\n%s", in, s)
+		}
+		clock = func() time.Time { return testNow.Add(16*time.Hour + 123*time.Millisecond) }
+		later := output(in)
+		clock = func() time.Time { return testNow }
+		if later != s {
+			t.Errorf("guess(%q) with -stable changed with the current time:\n%s\nlater:\n%s", in, s, later)
+		}
+	}
+}
+
+func TestSummarizeAttempts(t *testing.T) {
+	_, as := tryGuessers("1443346122")
+	if len(as) != len(guessers) {
+		t.Fatalf("tryGuessers() made %d attempts, want %d", len(as), len(guessers))
+	}
+	setFlag(t, "verbose", "true")
+	_, as = tryGuessers("foo bar")
+	for _, a := range as {
+		if a.name == "packedip" && !strings.HasPrefix(a.reason, `cannot parse "foo bar" as packed IPv4 address`) {
+			t.Errorf("tryGuessers() gave packedip the reason %q", a.reason)
+		}
+	}
+	got := summarizeAttempts([]attempt{
+		{name: "timestamp", likely: 2},
+		{name: "excel", unlikely: 1},
+		{name: "ip", reason: `"foo" is not an IP address`},
+		{name: "email"},
+	})
+	want := "Tried 4 guessers:\n" +
+		"    1 matched: timestamp\n" +
+		"    1 only unlikely guesses (see -unlikely): excel\n" +
+		"    2 input not in their format: ip, email\n" +
+		"        ip: \"foo\" is not an IP address\n"
+	if got != want {
+		t.Errorf("summarizeAttempts() = %q, want %q", got, want)
+	}
+}
+
+func TestOnlyGuessers(t *testing.T) {
+	setFlag(t, "only", "timestamp, date")
+	gs, as := tryGuessers("1443346122")
+	if len(as) != 2 || as[0].name != "timestamp" || as[1].name != "date" {
+		t.Errorf("tryGuessers() with -only made attempts %+v, want timestamp and date", as)
+	}
+	for _, g := range gs {
+		if !strings.HasPrefix(g.source, "timestamp") {
+			t.Errorf("tryGuessers() with -only timestamp,date guessed %+v", g)
+		}
+	}
+}
+
+func TestAdjustGoodness(t *testing.T) {
+	defer func() { adjustments = nil }()
+	for _, bad := range []string{"timestamp", "=+1", "bytes=lots", "bytes=*x"} {
+		if err := addAdjustment(bad); err == nil {
+			t.Errorf("addAdjustment(%q) succeeded", bad)
+		}
+	}
+
+	gs := guess("1443346122")
+	sort.Sort(ByGoodness(gs))
+	if gs[0].source == "byte count without explicit unit" {
+		t.Fatalf("guess() already ranks bytes first")
+	}
+	for _, a := range []string{"Byte Count=*2", "byte count=+500", "timestamp (seconds)=-1000"} {
+		if err := addAdjustment(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gs = guess("1443346122")
+	before := map[string]int{}
+	for _, g := range gs {
+		before[g.source] = g.goodness
+	}
+	adjustGoodness(gs)
+	sort.Sort(ByGoodness(gs))
+	if gs[0].source != "byte count without explicit unit" {
+		t.Errorf("adjustGoodness() ranks %q first", gs[0].source)
+	}
+	for _, g := range gs {
+		var want int
+		switch g.source {
+		case "byte count without explicit unit":
+			want = 2*before[g.source] + 500
+		case "timestamp (seconds)":
+			want = before[g.source] - 1000
+		default:
+			want = before[g.source]
+		}
+		if g.goodness != want {
+			t.Errorf("adjusted goodness of %q = %d, want %d", g.source, g.goodness, want)
+		}
+	}
+}
+
+func TestGuessTimeOnly(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess, comment string
+	}{
+		{"14:30", "Today in local time: 2015-09-27 14:30:00 +0000 UTC", "within the day, 5 hours 1 minute 17 seconds ahead"},
+		{"09:28:12.085", "Today in local time: 2015-09-27 09:28:12.085 +0000 UTC", "within the minute, 30 seconds ago"},
+		{"T02:00:00", "Today in local time: 2015-09-27 02:00:00 +0000 UTC", "within the day, 7 hours 28 minutes 42 seconds ago"},
+		{"11:28+02:00", "Today at 2015-09-27 11:28:00 +0200 +0200", "within the minute, 42 seconds ago"},
+	} {
+		gs := guessTimeOnly(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess || gs[0].comment != tc.comment {
+			t.Errorf("guessTimeOnly(%q) = %+v, want %q (%s)", tc.in, gs, tc.guess, tc.comment)
+		}
+	}
+	for _, s := range []string{"25:00", "14", "14:30:00:12", "2015-09-27"} {
+		if gs := guessTimeOnly(s); gs != nil {
+			t.Errorf("guessTimeOnly(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestZoneAbbreviations(t *testing.T) {
+	for _, tc := range []struct{ in, guess string }{
+		{"2015-09-26 11:29:43 PDT", "2015-09-26 11:29:43 -0700 PDT"},
+		{"2015-01-10 11:29:43 PST", "2015-01-10 11:29:43 -0800 PST"},
+		{"2015-09-26 11:29:43 CEST", "2015-09-26 11:29:43 +0200 CEST"},
+		{"2015-09-26 11:29:43 UTC", "2015-09-26 11:29:43 +0000 UTC"},
+		// The abbreviation is matched at the parsed date, not now, so
+		// both sides of a transition work, and so do abbreviations
+		// that are out of season at the date.
+		{"2015-11-01 01:30:00 PDT", "2015-11-01 01:30:00 -0700 PDT"},
+		{"2015-11-01 01:30:00 PST", "2015-11-01 01:30:00 -0800 PST"},
+		{"2015-03-08 01:59:59 PST", "2015-03-08 01:59:59 -0800 PST"},
+		{"2015-03-08 03:00:00 PDT", "2015-03-08 03:00:00 -0700 PDT"},
+		{"2015-10-25 02:30:00 CEST", "2015-10-25 02:30:00 +0200 CEST"},
+		{"2015-10-25 02:30:00 CET", "2015-10-25 02:30:00 +0100 CET"},
+		{"2015-07-01 12:00:00 PST", "2015-07-01 13:00:00 -0700 PDT"},
+		{"2015-01-15 12:00:00 PDT", "2015-01-15 11:00:00 -0800 PST"},
+	} {
+		gs := guessBuiltinDate(tc.in)
+		if len(gs) == 0 || gs[0].guess != tc.guess {
+			t.Errorf("guessBuiltinDate(%q) = %+v, want %q", tc.in, gs, tc.guess)
+		}
+	}
+	if locs := zonesWithAbbreviation("PDT"); len(locs) != 1 || locs[0].String() != "America/Los_Angeles" {
+		t.Errorf("zonesWithAbbreviation(PDT) = %v", locs)
+	}
+	if locs := zonesWithAbbreviation("LMT"); len(locs) != len(TZs) {
+		t.Errorf("zonesWithAbbreviation(LMT) = %v, want all of %v", locs, TZs)
+	}
+
+	old := TZs
+	defer func() { TZs = old }()
+	var err error
+	TZs, err = loadTimezones("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locs := zonesWithAbbreviation("PDT"); len(locs) != 1 || locs[0].String() != "Europe/Berlin" {
+		t.Errorf("zonesWithAbbreviation(PDT) after changing TZs = %v, want just Europe/Berlin", locs)
+	}
+}
+
+// layoutMayMatch must never rule out a layout that would have matched.
+func TestLayoutMayMatch(t *testing.T) {
+	var layouts []string
+	for _, ls := range [][]string{goodTZformats, badTZformats, twoDigitYearFormats, timeOnlyFormats} {
+		layouts = append(layouts, ls...)
+	}
+	for _, l := range layouts {
+		for _, d := range []time.Time{testNow, time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)} {
+			if s := d.Format(l); !layoutMayMatch(l, s) {
+				t.Errorf("layoutMayMatch(%q, %q) = false", l, s)
+			}
+		}
+	}
+	if layoutMayMatch(time.RFC3339, "Sep 27") || layoutMayMatch(time.RubyDate, "2015-09-27") || layoutMayMatch("01/02/2006", "01.02.2006") {
+		t.Error("layoutMayMatch() doesn't rule anything out")
+	}
+}
+
+var benchInputs = []string{
+	"2015-09-26 11:29:43 PDT",
+	"2015-09-26T11:29:43Z",
+	"Sep 26 11:29:43",
+	"1443346122",
+	"xyzzy",
+}
+
+func BenchmarkGuessDate(b *testing.B) {
+	for _, in := range benchInputs {
+		b.Run(in, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				guessDate(in)
+			}
+		})
+	}
+}
+
+func BenchmarkGuess(b *testing.B) {
+	for _, in := range benchInputs {
+		b.Run(in, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				guess(in)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// guessICalDate decodes RFC 5545 DATE and DATE-TIME values as found in
+// .ics files, either bare like 20150926T112943Z or as a whole property
+// like DTSTART;TZID=Europe/Berlin:20150926T112943.  A DATE-TIME without Z
+// or TZID is "floating", i.e. in whatever time zone the reader is in.
+func guessICalDate(s string) []Guess {
+	value, tzid, isDate := s, "", false
+	if i := strings.LastIndexByte(s, ':'); i >= 0 {
+		value = s[i+1:]
+		for _, p := range strings.Split(s[:i], ";") {
+			k, v, ok := strings.Cut(p, "=")
+			switch {
+			case !ok:
+				// The property name, e.g. DTSTART.
+			case strings.EqualFold(k, "TZID"):
+				tzid = strings.Trim(v, `"`)
+			case strings.EqualFold(k, "VALUE"):
+				isDate = strings.EqualFold(v, "DATE")
+			}
+		}
+	} else if !strings.Contains(s, "T") {
+		// A bare 8 digit DATE is more likely just a number.
+		return nil
+	}
+
+	if isDate {
+		d, err := time.Parse("20060102", value)
+		if err != nil {
+			trace("error parsing as iCalendar DATE: %v", err)
+			return nil
+		}
+		g := dayGuess(d)
+		g.source = "iCalendar DATE"
+		g.goodness += 150
+		return []Guess{g}
+	}
+
+	var d time.Time
+	var err error
+	var source string
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		if tzid != "" {
+			// RFC 5545 doesn't allow TZID on UTC times.
+			return nil
+		}
+		d, err = time.Parse("20060102T150405Z", value)
+		source = "iCalendar DATE-TIME in UTC"
+	case tzid != "":
+		loc, lerr := time.LoadLocation(tzid)
+		if lerr != nil {
+			trace("unknown iCalendar TZID: %v", lerr)
+			return nil
+		}
+		d, err = time.ParseInLocation("20060102T150405", value, loc)
+		source = "iCalendar DATE-TIME with TZID " + tzid
+	default:
+		d, err = time.ParseInLocation("20060102T150405", value, time.Local)
+		source = "iCalendar floating DATE-TIME in local time"
+	}
+	if err != nil {
+		trace("error parsing as iCalendar DATE-TIME: %v", err)
+		return nil
+	}
+	g := dateGuess(d)
+	g.source = source
+	return []Guess{g}
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestGuessICalDate(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess, source string
+	}{
+		{"20150926T112943Z", "2015-09-26 11:29:43 +0000 UTC", "iCalendar DATE-TIME in UTC"},
+		{"DTSTART:20150926T112943Z", "2015-09-26 11:29:43 +0000 UTC", "iCalendar DATE-TIME in UTC"},
+		{"TZID=Europe/Berlin:20150926T112943", "2015-09-26 11:29:43 +0200 CEST", "iCalendar DATE-TIME with TZID Europe/Berlin"},
+		{`DTSTART;TZID="America/New_York":20151226T112943`, "2015-12-26 11:29:43 -0500 EST", "iCalendar DATE-TIME with TZID America/New_York"},
+		{"20150926T112943", "2015-09-26 11:29:43 +0000 UTC", "iCalendar floating DATE-TIME in local time"},
+		{"DTEND;VALUE=DATE:20150926", "Saturday, 2015-09-26", "iCalendar DATE"},
+	} {
+		gs := guessICalDate(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess || gs[0].source != tc.source {
+			t.Errorf("guessICalDate(%q) = %+v, want %q from %q", tc.in, gs, tc.guess, tc.source)
+		}
+	}
+	for _, s := range []string{
+		"20150926",
+		"TZID=Nowhere/Special:20150926T112943",
+		"TZID=Europe/Berlin:20150926T112943Z",
+		"20151326T112943Z",
+		"DTSTART:hello",
+	} {
+		if gs := guessICalDate(s); gs != nil {
+			t.Errorf("guessICalDate(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func guessGitSHA(s string) []Guess {
+	if !isHex(s) {
+		return nil
+	}
+	var g Guess
+	switch n := len(s); {
+	case n == 64:
+		g = Guess{
+			guess:      "Looks like a Git object ID " + strings.ToLower(s),
+			comment:    "SHA-256 object format",
+			additional: []string{"full SHA-256 Git commit/blob/tree ID"},
+			goodness:   80,
+		}
+	case n == 40:
+		g = Guess{
+			guess:      "Looks like a Git commit/blob SHA " + strings.ToLower(s),
+			comment:    "SHA-1",
+			additional: []string{"full SHA-1 Git object ID"},
+			goodness:   120,
+		}
+	case n >= 7 && n < 40:
+		g = Guess{
+			guess:      "Looks like an abbreviated Git commit/blob SHA " + strings.ToLower(s),
+			comment:    "SHA-1",
+			additional: []string{fmt.Sprintf("abbreviated to %d of 40 hex digits", n)},
+			goodness:   50,
+		}
+		if n < 12 {
+			// Git's default abbreviation length, but short hex strings
+			// could be just about anything.
+			g.goodness = 20
+		}
+	default:
+		return nil
+	}
+	if strings.Trim(s, "0123456789") == "" {
+		// Without any a-f digits it is far more likely to be a number.
+		g.goodness = -10
+	}
+	g.source = "Git object ID"
+	return []Guess{g}
+}
+
+// Digest algorithms as used in OCI image manifests, with the number of hex
+// digits in their encoded form.
+var ociDigestAlgorithms = map[string]struct {
+	digits int
+	name   string
+}{
+	"sha256": {64, "SHA-256"},
+	"sha384": {96, "SHA-384"},
+	"sha512": {128, "SHA-512"},
+}
+
+func guessContainerID(s string) []Guess {
+	if alg, hexpart, ok := strings.Cut(s, ":"); ok {
+		a, known := ociDigestAlgorithms[strings.ToLower(alg)]
+		if !known || len(hexpart) != a.digits || !isHex(hexpart) {
+			return nil
+		}
+		return []Guess{{
+			guess:   "Container image digest " + strings.ToLower(s),
+			comment: "digest algorithm: " + a.name,
+			additional: []string{
+				"as used by docker images --digests and OCI manifests",
+				fmt.Sprintf("short form: %s", hexpart[:12]),
+			},
+			source:   "OCI image digest",
+			goodness: 200,
+		}}
+	}
+
+	if !isHex(s) {
+		return nil
+	}
+	switch len(s) {
+	case 12:
+		return []Guess{{
+			guess:      "Container or image ID " + strings.ToLower(s),
+			comment:    "short form",
+			additional: []string{"truncated SHA-256 as shown by docker ps and docker images"},
+			source:     "container ID",
+			goodness:   10,
+		}}
+	case 64:
+		return []Guess{{
+			guess:   "Container or image ID " + strings.ToLower(s),
+			comment: "digest algorithm: SHA-256",
+			additional: []string{
+				fmt.Sprintf("short form: %s", s[:12]),
+			},
+			source:   "container ID",
+			goodness: 70,
+		}}
+	}
+	return nil
+}
+
+// BICs are 4 letters for the bank, 2 for the country, 2 letters or digits
+// for the location and optionally 3 for the branch.
+var bicRE = regexp.MustCompile(`^([A-Z]{4})([A-Z]{2})([A-Z0-9]{2})([A-Z0-9]{3})?$`)
+
+// guessBIC recognizes SWIFT/BIC codes like DEUTDEFF500.  Only the structure
+// and country are checked, not whether the code is actually registered.
+func guessBIC(s string) []Guess {
+	m := bicRE.FindStringSubmatch(s)
+	if m == nil {
+		return nil
+	}
+	country := countries[m[2]]
+	if country == "" {
+		trace("%q is not a BIC, %s is not a country", s, m[2])
+		return nil
+	}
+	additional := []string{
+		"bank: " + m[1],
+		fmt.Sprintf("country: %s (%s)", m[2], country),
+		"location: " + m[3],
+	}
+	switch m[3][1] {
+	case '0':
+		additional = append(additional, "test BIC, not used in production")
+	case '1':
+		additional = append(additional, "passive participant")
+	}
+	switch m[4] {
+	case "":
+		additional = append(additional, "no branch, i.e. primary office")
+	case "XXX":
+		additional = append(additional, "branch: XXX, i.e. primary office")
+	default:
+		additional = append(additional, "branch: "+m[4])
+	}
+	g := Guess{
+		guess:      "SWIFT/BIC code " + s,
+		comment:    "not checked to be registered",
+		additional: additional,
+		source:     "BIC",
+		goodness:   100,
+	}
+	if m[4] == "" {
+		// Plenty of 8 letter words pass the structural check.
+		g.goodness = 50
+	}
+	return []Guess{g}
+}
+
+// Well-known Windows security identifiers, see
+// https://learn.microsoft.com/en-us/windows/win32/secauthz/well-known-sids
+var wellKnownSIDs = map[string]string{
+	"S-1-0-0":      "Nobody",
+	"S-1-1-0":      "Everyone",
+	"S-1-2-0":      "Local",
+	"S-1-2-1":      "Console Logon",
+	"S-1-3-0":      "Creator Owner",
+	"S-1-3-1":      "Creator Group",
+	"S-1-5-1":      "Dialup",
+	"S-1-5-2":      "Network",
+	"S-1-5-3":      "Batch",
+	"S-1-5-4":      "Interactive",
+	"S-1-5-6":      "Service",
+	"S-1-5-7":      "Anonymous Logon",
+	"S-1-5-9":      "Enterprise Domain Controllers",
+	"S-1-5-10":     "Principal Self",
+	"S-1-5-11":     "Authenticated Users",
+	"S-1-5-18":     "Local System",
+	"S-1-5-19":     "Local Service",
+	"S-1-5-20":     "Network Service",
+	"S-1-5-32":     "Builtin",
+	"S-1-5-32-544": "Builtin Administrators",
+	"S-1-5-32-545": "Builtin Users",
+	"S-1-5-32-546": "Builtin Guests",
+	"S-1-5-32-547": "Power Users",
+	"S-1-5-32-551": "Backup Operators",
+	"S-1-5-32-555": "Remote Desktop Users",
+	"S-1-16-4096":  "Low Mandatory Level",
+	"S-1-16-8192":  "Medium Mandatory Level",
+	"S-1-16-12288": "High Mandatory Level",
+	"S-1-16-16384": "System Mandatory Level",
+}
+
+// Well-known relative IDs of domain and machine accounts, S-1-5-21-...-RID.
+var wellKnownRIDs = map[uint64]string{
+	500: "Administrator",
+	501: "Guest",
+	502: "krbtgt",
+	512: "Domain Admins",
+	513: "Domain Users",
+	514: "Domain Guests",
+	515: "Domain Computers",
+	516: "Domain Controllers",
+	518: "Schema Admins",
+	519: "Enterprise Admins",
+	520: "Group Policy Creator Owners",
+}
+
+var sidAuthorities = map[uint64]string{
+	0:  "Null",
+	1:  "World",
+	2:  "Local",
+	3:  "Creator",
+	5:  "NT Authority",
+	16: "Mandatory Label",
+}
+
+// guessSID recognizes Windows security identifiers like S-1-5-18.
+func guessSID(s string) []Guess {
+	if !strings.HasPrefix(s, "S-1-") {
+		return nil
+	}
+	parts := strings.Split(s, "-")
+	if len(parts) < 3 || len(parts) > 3+15 {
+		return nil
+	}
+	var nums []uint64
+	for i, p := range parts[1:] {
+		base, bits := 10, 32
+		if i == 1 {
+			bits = 48
+			if strings.HasPrefix(p, "0x") {
+				base, p = 16, p[2:]
+			}
+		}
+		n, err := strconv.ParseUint(p, base, bits)
+		if err != nil {
+			trace("cannot parse %q as SID: %v", s, err)
+			return nil
+		}
+		nums = append(nums, n)
+	}
+	authority := sidAuthorities[nums[1]]
+	if authority == "" {
+		authority = "unknown"
+	}
+	additional := []string{
+		fmt.Sprintf("revision: %d", nums[0]),
+		fmt.Sprintf("identifier authority: %d (%s)", nums[1], authority),
+	}
+	subs := nums[2:]
+	if len(subs) > 0 {
+		var ss []string
+		for _, n := range subs {
+			ss = append(ss, strconv.FormatUint(n, 10))
+		}
+		additional = append(additional, "sub-authorities: "+strings.Join(ss, ", "))
+	}
+	name := wellKnownSIDs[s]
+	if len(subs) >= 2 && subs[0] == 21 && nums[1] == 5 {
+		rid := subs[len(subs)-1]
+		account := wellKnownRIDs[rid]
+		switch {
+		case account != "":
+		case rid >= 1000:
+			account = "user or group created by an administrator"
+		default:
+			account = "built-in account"
+		}
+		additional = append(additional, fmt.Sprintf("relative ID: %d (%s)", rid, account))
+		if name == "" && len(subs) == 5 {
+			name = "domain or machine account"
+		}
+	}
+	return []Guess{{
+		guess:      "Windows security identifier " + s,
+		comment:    name,
+		additional: additional,
+		source:     "Windows SID",
+		goodness:   200,
+	}}
+}
+
+// Crockford's base32 alphabet, as used by ULIDs; see
+// https://www.crockford.com/base32.html
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// crockfordValue returns the value of a Crockford base32 digit, reading
+// the easily confused I, L and O as 1, 1 and 0, or -1 if c isn't one.
+func crockfordValue(c rune) int {
+	switch c {
+	case 'i', 'I', 'l', 'L':
+		return 1
+	case 'o', 'O':
+		return 0
+	}
+	if c >= 'a' && c <= 'z' {
+		c -= 'a' - 'A'
+	}
+	return strings.IndexRune(crockfordAlphabet, c)
+}
+
+// crockfordDecode returns the number that s stands for in Crockford base32
+// and s with the digits in canonical form, or false if s has other
+// characters.
+func crockfordDecode(s string) (*big.Int, string, bool) {
+	n := new(big.Int)
+	var norm strings.Builder
+	for _, c := range s {
+		v := crockfordValue(c)
+		if v < 0 {
+			return nil, "", false
+		}
+		n.Lsh(n, 5)
+		n.Or(n, big.NewInt(int64(v)))
+		norm.WriteByte(crockfordAlphabet[v])
+	}
+	return n, norm.String(), true
+}
+
+// isSlug is true for hyphenated words like hello-world-2015, as in URLs,
+// where at least one part is a word of letters only.
+func isSlug(s string) bool {
+	parts := strings.Split(s, "-")
+	if len(parts) < 2 {
+		return false
+	}
+	for _, part := range parts {
+		if len(part) > 1 && strings.IndexFunc(part, func(r rune) bool { return !unicode.IsLetter(r) }) < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Dates and iCal times, like 2015-09-26T11 or 20150926T112943Z.
+var dateShapeRE = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}|\d{8}T\d{6}`)
+
+// hasOtherReading is true for strings of Crockford digits that are more
+// likely something else, like the durations 1h30m45s and 1443346122ms or
+// dates.
+func hasOtherReading(s string) bool {
+	if _, err := time.ParseDuration(s); err == nil {
+		return true
+	}
+	return dateShapeRE.MatchString(s)
+}
+
+// guessCrockford decodes other Crockford base32 IDs into the integer they
+// stand for, ignoring the hyphens that may be added for readability.  Plain
+// words and numbers are in the alphabet too, so it takes both digits and
+// letters, and short strings, slugs, ones that could as well be hex and
+// ones with another reading are only unlikely guesses.
+func guessCrockford(s string) []Guess {
+	digits := strings.ReplaceAll(s, "-", "")
+	if len(digits) < 4 || len(s) == 26 || !strings.ContainsAny(digits, "0123456789") || strings.Trim(digits, "0123456789") == "" {
+		return nil
+	}
+	n, norm, ok := crockfordDecode(digits)
+	if !ok {
+		return nil
+	}
+	g := Guess{
+		guess:      "Crockford base32 number " + n.String(),
+		additional: []string{fmt.Sprintf("hex: 0x%x (%d bits)", n, n.BitLen())},
+		source:     "Crockford base32",
+		goodness:   30,
+	}
+	if norm != digits {
+		g.comment = "as " + norm
+	}
+	if len(digits) < 8 || isHex(digits) || isSlug(s) || hasOtherReading(s) {
+		g.goodness = -5
+	}
+	return []Guess{g}
+}
+
+// guessULID decodes the millisecond timestamp in the first 10 of the 26
+// base32 digits of a ULID; the other 16 are random.
+func guessULID(s string) []Guess {
+	if len(s) != 26 || s[0] > '7' {
+		return nil
+	}
+	if _, _, ok := crockfordDecode(s); !ok {
+		return nil
+	}
+	n, _, _ := crockfordDecode(s[:10])
+	ms := n.Uint64()
+	t := time.UnixMilli(int64(ms))
+	g := dateGuess(t)
+	g.guess = "ULID with timestamp " + g.guess
+	g.additional = append([]string{
+		fmt.Sprintf("timestamp: %s (%d ms)", s[:10], ms),
+		fmt.Sprintf("randomness: %s (80 bits)", s[10:]),
+	}, g.additional...)
+	g.source = "ULID"
+	if t.Year() >= 1990 && t.Year() < 2100 {
+		g.goodness = 150
+	} else {
+		g.goodness = 20
+	}
+	return []Guess{g}
+}
+
+// guessObjectID decodes MongoDB ObjectIDs: 12 bytes in hex, of which the
+// first 4 are the creation time in seconds since 1970, then 5 random bytes
+// (a machine ID and process ID before MongoDB 3.4) and a 3 byte counter.
+func guessObjectID(s string) []Guess {
+	if len(s) != 24 || !isHex(s) {
+		return nil
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil
+	}
+	t := time.Unix(int64(binary.BigEndian.Uint32(b)), 0)
+	g := dateGuess(t)
+	g.guess = "MongoDB ObjectID created " + g.guess
+	g.additional = append([]string{
+		fmt.Sprintf("random value: %x (machine %x, process %x before MongoDB 3.4)", b[4:9], b[4:7], b[7:9]),
+		fmt.Sprintf("counter: %d", uint32(b[9])<<16|uint32(b[10])<<8|uint32(b[11])),
+	}, g.additional...)
+	g.source = "MongoDB ObjectID"
+	// MongoDB was first released in 2009.
+	if t.Year() >= 2009 && t.Before(now().Add(24*time.Hour)) {
+		g.goodness = 150
+	} else {
+		g.goodness = 10
+	}
+	return []Guess{g}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGuessGitSHA(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		good int
+	}{
+		{"3390ae5", 20},
+		{"3390ae5f3f0c", 50},
+		{"3390ae5f3f0c1e2d3c4b5a69788796a5b4c3d2e1", 120},
+		{"73cb3858a687a8494ca3323053016282f3dad39d42cf62ca4e79dda2aac7d9ac", 80},
+		{"1234567", -10},
+	} {
+		gs := guessGitSHA(tc.in)
+		if len(gs) != 1 || gs[0].goodness != tc.good {
+			t.Errorf("guessGitSHA(%q) = %+v, want goodness %d", tc.in, gs, tc.good)
+		}
+	}
+	for _, s := range []string{"abc", "3390ae5x", "3390ae5f3f0c1e2d3c4b5a69788796a5b4c3d2e1aa"} {
+		if gs := guessGitSHA(s); gs != nil {
+			t.Errorf("guessGitSHA(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessContainerID(t *testing.T) {
+	const digest = "73cb3858a687a8494ca3323053016282f3dad39d42cf62ca4e79dda2aac7d9ac"
+	for _, tc := range []struct {
+		in, comment string
+		good        int
+	}{
+		{"sha256:" + digest, "digest algorithm: SHA-256", 200},
+		{digest, "digest algorithm: SHA-256", 70},
+		{digest[:12], "short form", 10},
+	} {
+		gs := guessContainerID(tc.in)
+		if len(gs) != 1 || gs[0].comment != tc.comment || gs[0].goodness != tc.good {
+			t.Errorf("guessContainerID(%q) = %+v", tc.in, gs)
+		}
+	}
+	for _, s := range []string{"sha256:" + digest[:63], "md5:" + digest, digest[:13]} {
+		if gs := guessContainerID(s); gs != nil {
+			t.Errorf("guessContainerID(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessBIC(t *testing.T) {
+	for _, tc := range []struct {
+		in, country string
+		goodness    int
+	}{
+		{"DEUTDEFF500", "country: DE (Germany)", 100},
+		{"DEUTDEFF", "country: DE (Germany)", 50},
+		{"NWBKGB2LXXX", "country: GB (United Kingdom)", 100},
+		{"BNPAFRPP", "country: FR (France)", 50},
+	} {
+		gs := guessBIC(tc.in)
+		if len(gs) != 1 || gs[0].additional[1] != tc.country || gs[0].goodness != tc.goodness {
+			t.Errorf("guessBIC(%q) = %+v, want %q with goodness %d", tc.in, gs, tc.country, tc.goodness)
+		}
+	}
+	for _, s := range []string{"DEUTXXFF", "deutdeff", "DEUTDEFF50", "DEUT1EFF", "DEUTDEFF5000", "ABCDZZ12"} {
+		if gs := guessBIC(s); gs != nil {
+			t.Errorf("guessBIC(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessSID(t *testing.T) {
+	for _, tc := range []struct {
+		in, comment, last string
+	}{
+		{"S-1-5-18", "Local System", "sub-authorities: 18"},
+		{"S-1-5-32-544", "Builtin Administrators", "sub-authorities: 32, 544"},
+		{"S-1-1-0", "Everyone", "sub-authorities: 0"},
+		{"S-1-5-21-3623811015-3361044348-30300820-1013", "domain or machine account", "relative ID: 1013 (user or group created by an administrator)"},
+		{"S-1-5-21-3623811015-3361044348-30300820-500", "domain or machine account", "relative ID: 500 (Administrator)"},
+		{"S-1-0x1000000000-1", "", "sub-authorities: 1"},
+	} {
+		gs := guessSID(tc.in)
+		if len(gs) != 1 || gs[0].comment != tc.comment || gs[0].additional[len(gs[0].additional)-1] != tc.last {
+			t.Errorf("guessSID(%q) = %+v, want %q, %q", tc.in, gs, tc.comment, tc.last)
+		}
+	}
+	for _, s := range []string{"S-1-", "S-1-x", "s-1-5-18", "S-2-5-18", "S-1-5-4294967296"} {
+		if gs := guessSID(s); gs != nil {
+			t.Errorf("guessSID(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessULID(t *testing.T) {
+	gs := guessULID("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	if len(gs) != 1 {
+		t.Fatalf("guessULID() = %+v, want one guess", gs)
+	}
+	g := gs[0]
+	if want := "ULID with timestamp 2016-07-30 23:54:10.259 +0000 UTC"; g.guess != want {
+		t.Errorf("guessULID() = %q, want %q", g.guess, want)
+	}
+	if want := "timestamp: 01ARZ3NDEK (1469922850259 ms)"; g.additional[0] != want || g.goodness != 150 {
+		t.Errorf("guessULID() = %+v, want %q", g, want)
+	}
+	if gs := guessULID("01arz3ndektsv4rrffq69g5fav"); len(gs) != 1 || gs[0].guess != g.guess {
+		t.Errorf("guessULID() lower case = %+v", gs)
+	}
+	for _, s := range []string{"01ARZ3NDEKTSV4RRFFQ69G5FA", "81ARZ3NDEKTSV4RRFFQ69G5FAV", "01ARZ3NDEKTSV4RRFFQ69G5FAU"} {
+		if gs := guessULID(s); gs != nil {
+			t.Errorf("guessULID(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessObjectID(t *testing.T) {
+	gs := guessObjectID("5607b6ca1a2b3c4d5e000102")
+	if len(gs) != 1 {
+		t.Fatalf("guessObjectID() = %+v, want one guess", gs)
+	}
+	want := []string{
+		"random value: 1a2b3c4d5e (machine 1a2b3c, process 4d5e before MongoDB 3.4)",
+		"counter: 258",
+	}
+	if g := gs[0]; g.guess != "MongoDB ObjectID created 2015-09-27 09:28:42 +0000 UTC" || !reflect.DeepEqual(g.additional[:2], want) || g.goodness != 150 {
+		t.Errorf("guessObjectID() = %+v", g)
+	}
+	if gs := guessObjectID("000000001a2b3c4d5e000102"); len(gs) != 1 || gs[0].goodness != 10 {
+		t.Errorf("guessObjectID() from 1970 = %+v, want goodness 10", gs)
+	}
+	for _, s := range []string{"5607b6ca1a2b3c4d5e0001", "5607b6ca1a2b3c4d5e00010z"} {
+		if gs := guessObjectID(s); gs != nil {
+			t.Errorf("guessObjectID(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessCrockford(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess, comment string
+		goodness           int
+	}{
+		{"3ZNQK8G1", "Crockford base32 number 137094603265", "", 30},
+		{"3znq-k8g1", "Crockford base32 number 137094603265", "as 3ZNQK8G1", 30},
+		{"3ZNQK8GI", "Crockford base32 number 137094603265", "as 3ZNQK8G1", 30},
+		{"1O2L", "Crockford base32 number 32833", "as 1021", -5},
+		{"3390ae5f", "Crockford base32 number 106602772655", "as 3390AE5F", -5},
+		{"hello-world-2015", "Crockford base32 number 643367385079689052197", "as HE110W0R1D2015", -5},
+		{"1h30m45s", "Crockford base32 number 52714672313", "as 1H30M45S", -5},
+	} {
+		gs := guessCrockford(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess || gs[0].comment != tc.comment || gs[0].goodness != tc.goodness {
+			t.Errorf("guessCrockford(%q) = %+v, want %q (%s) with goodness %d", tc.in, gs, tc.guess, tc.comment, tc.goodness)
+		}
+	}
+	if gs := guessCrockford("ZZZZZZZZZZZZZZZ9"); len(gs) != 1 || gs[0].guess != "Crockford base32 number 1208925819614629174706153" {
+		t.Errorf("guessCrockford() of an 80 bit number = %+v", gs)
+	}
+	for _, s := range []string{"20150926T112943Z", "1443346122ms", "2015-09-26T11"} {
+		if gs := guessCrockford(s); len(gs) != 1 || gs[0].goodness >= 0 {
+			t.Errorf("guessCrockford(%q) = %+v, want an unlikely guess", s, gs)
+		}
+	}
+	if gs := guessCrockford("3ZNQK8G1"); len(gs) != 1 || gs[0].additional[0] != "hex: 0x1feb79a201 (37 bits)" {
+		t.Errorf("guessCrockford(3ZNQK8G1) = %+v, want 37 bits", gs)
+	}
+	for _, s := range []string{"hello", "12345678", "ab1", "3ZNQ+K8G1", "U2345678", "01ARZ3NDEKTSV4RRFFQ69G5FAV"} {
+		if gs := guessCrockford(s); gs != nil {
+			t.Errorf("guessCrockford(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// At most this many lines of pretty-printed JSON are shown.
+const maxJSONLines = 40
+
+// guessJSON recognizes JSON objects and arrays and pretty-prints them.
+// Bare numbers, strings and literals are valid JSON too, but better left to
+// the other guessers.
+func guessJSON(s string) []Guess {
+	if !strings.HasPrefix(s, "{") && !strings.HasPrefix(s, "[") {
+		return nil
+	}
+	if !json.Valid([]byte(s)) {
+		trace("%q is not valid JSON", s)
+		return nil
+	}
+	var v interface{}
+	d := json.NewDecoder(strings.NewReader(s))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil
+	}
+	var n int
+	var what, guess string
+	switch v := v.(type) {
+	case map[string]interface{}:
+		n, what, guess = len(v), "key", "JSON object"
+	case []interface{}:
+		n, what, guess = len(v), "element", "JSON array"
+	}
+	plural := "s"
+	if n == 1 {
+		plural = ""
+	}
+
+	var buf bytes.Buffer
+	json.Indent(&buf, []byte(s), "", "  ")
+	lines := strings.Split(buf.String(), "\n")
+	if len(lines) > maxJSONLines {
+		more := len(lines) - maxJSONLines
+		lines = append(lines[:maxJSONLines], fmt.Sprintf("... %d more lines", more))
+	}
+	g := Guess{
+		guess:      guess,
+		comment:    fmt.Sprintf("%d top-level %s%s", n, what, plural),
+		additional: lines,
+		source:     "JSON",
+		goodness:   180,
+	}
+	if n == 0 {
+		g.goodness = 50
+	}
+	return []Guess{g}
+}
+
+// guessBase64JSON decodes base64url like eyJhIjoxfQ that turns out to be
+// a JSON object or array, as found in OAuth state parameters, cookies and
+// all kinds of tokens.  Padding and the standard alphabet are accepted too.
+func guessBase64JSON(s string) []Guess {
+	if len(s) < 4 || strings.ContainsAny(s, ". ") {
+		return nil
+	}
+	var b []byte
+	var err error
+	for _, e := range []*base64.Encoding{base64.RawURLEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.StdEncoding} {
+		if b, err = e.DecodeString(s); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil
+	}
+	gs := guessJSON(strings.TrimSpace(string(b)))
+	for i := range gs {
+		gs[i].guess = "Base64-encoded " + gs[i].guess
+		gs[i].source = "base64url JSON"
+	}
+	return gs
+}
+
+// extractField returns the value at path in the JSON document s, which may
+// also be base64url-encoded, for -field.  The path consists of object keys
+// and array indexes separated by dots, like items.0.exp.  Strings are
+// returned without quotes, other values as JSON.
+func extractField(s, path string) (string, error) {
+	doc := []byte(s)
+	if !json.Valid(doc) {
+		for _, e := range []*base64.Encoding{base64.RawURLEncoding, base64.URLEncoding} {
+			if b, err := e.DecodeString(s); err == nil && json.Valid(b) {
+				doc = b
+				break
+			}
+		}
+	}
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(doc))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return "", fmt.Errorf("input is not JSON: %v", err)
+	}
+	var at []string
+	for _, key := range strings.Split(path, ".") {
+		switch c := v.(type) {
+		case map[string]interface{}:
+			val, ok := c[key]
+			if !ok {
+				return "", fmt.Errorf("no key %q in %s", key, describePath(at))
+			}
+			v = val
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(c) {
+				return "", fmt.Errorf("no index %q in %s of %d elements", key, describePath(at), len(c))
+			}
+			v = c[i]
+		default:
+			return "", fmt.Errorf("%s is neither an object nor an array", describePath(at))
+		}
+		at = append(at, key)
+	}
+	if str, ok := v.(string); ok {
+		return str, nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func describePath(at []string) string {
+	if len(at) == 0 {
+		return "the top level"
+	}
+	return strings.Join(at, ".")
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGuessJSON(t *testing.T) {
+	gs := guessJSON(`{"a": 1, "b": [true, null]}`)
+	if len(gs) != 1 {
+		t.Fatalf("guessJSON() = %+v, want one guess", gs)
+	}
+	want := []string{
+		"{",
+		`  "a": 1,`,
+		`  "b": [`,
+		"    true,",
+		"    null",
+		"  ]",
+		"}",
+	}
+	if g := gs[0]; g.guess != "JSON object" || g.comment != "2 top-level keys" || !reflect.DeepEqual(g.additional, want) {
+		t.Errorf("guessJSON() = %+v", g)
+	}
+
+	gs = guessJSON("[1]")
+	if len(gs) != 1 || gs[0].comment != "1 top-level element" || gs[0].goodness != 180 {
+		t.Errorf("guessJSON([1]) = %+v", gs)
+	}
+	gs = guessJSON("{}")
+	if len(gs) != 1 || gs[0].goodness != 50 {
+		t.Errorf("guessJSON({}) = %+v", gs)
+	}
+	gs = guessJSON("[" + strings.Repeat("1,", 100) + "1]")
+	if len(gs) != 1 || len(gs[0].additional) != maxJSONLines+1 || gs[0].additional[maxJSONLines] != "... 63 more lines" {
+		t.Errorf("guessJSON(long array) = %+v", gs)
+	}
+
+	for _, s := range []string{"42", `"str"`, "true", "{", `{"a":}`, "[1] [2]"} {
+		if gs := guessJSON(s); gs != nil {
+			t.Errorf("guessJSON(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessBase64JSON(t *testing.T) {
+	gs := guessBase64JSON("eyJzdGF0ZSI6ImFiYyIsIm4iOjF9")
+	if len(gs) != 1 || gs[0].guess != "Base64-encoded JSON object" || gs[0].comment != "2 top-level keys" || gs[0].source != "base64url JSON" {
+		t.Errorf("guessBase64JSON() = %+v", gs)
+	}
+	// {"k":"ÿ?"} has a - and _ in base64url.
+	if gs := guessBase64JSON("eyJrIjoiw78_In0="); len(gs) != 1 || gs[0].guess != "Base64-encoded JSON object" {
+		t.Errorf("guessBase64JSON() with URL alphabet = %+v", gs)
+	}
+	for _, s := range []string{"aGVsbG8gd29ybGQ", "eyJhIjox", "eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.sig", "!!!!"} {
+		if gs := guessBase64JSON(s); gs != nil {
+			t.Errorf("guessBase64JSON(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestExtractField(t *testing.T) {
+	doc := `{"sub": "joe", "exp": 1443346122, "items": [{"id": "a"}, {"id": "b", "tags": ["x"]}], "n": null}`
+	for _, tc := range []struct{ path, want string }{
+		{"sub", "joe"},
+		{"exp", "1443346122"},
+		{"items.1.id", "b"},
+		{"items.1", `{"id":"b","tags":["x"]}`},
+		{"items.1.tags", `["x"]`},
+		{"n", "null"},
+	} {
+		got, err := extractField(doc, tc.path)
+		if err != nil || got != tc.want {
+			t.Errorf("extractField(%q) = %q, %v, want %q", tc.path, got, err, tc.want)
+		}
+	}
+	if got, err := extractField("eyJleHAiOjE0NDMzNDYxMjJ9", "exp"); err != nil || got != "1443346122" {
+		t.Errorf("extractField() from base64url = %q, %v", got, err)
+	}
+	for _, tc := range []struct{ in, path, err string }{
+		{doc, "iat", `no key "iat" in the top level`},
+		{doc, "items.2.id", `no index "2" in items of 2 elements`},
+		{doc, "sub.name", "sub is neither an object nor an array"},
+		{"xyzzy", "sub", "input is not JSON: invalid character 'x' looking for beginning of value"},
+	} {
+		if _, err := extractField(tc.in, tc.path); err == nil || err.Error() != tc.err {
+			t.Errorf("extractField(%q) error = %v, want %q", tc.path, err, tc.err)
+		}
+	}
+}
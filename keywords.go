@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keywords for points in time relative to now.  Besides these, offsets
+// like +3d, -2h or +1h30m are understood, with the units w (weeks), d
+// (days) and everything time.ParseDuration accepts.
+var keywords = map[string]func(time.Time) time.Time{
+	"now":       func(t time.Time) time.Time { return t },
+	"today":     func(t time.Time) time.Time { return startOfDay(t) },
+	"yesterday": func(t time.Time) time.Time { return startOfDay(t).AddDate(0, 0, -1) },
+	"tomorrow":  func(t time.Time) time.Time { return startOfDay(t).AddDate(0, 0, 1) },
+	"noon":      func(t time.Time) time.Time { return startOfDay(t).Add(12 * time.Hour) },
+	"midnight":  func(t time.Time) time.Time { return startOfDay(t) },
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+var offsetRE = regexp.MustCompile(`^([+-])(\d+)([wd])$`)
+
+// editDistance returns the optimal string alignment distance between a and
+// b, i.e. the number of insertions, deletions, substitutions and
+// transpositions of adjacent characters needed to turn one into the other.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// keywordNames returns the keywords, sorted so that typos match the same
+// one every time.
+func keywordNames() []string {
+	var names []string
+	for k := range keywords {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// matchKeyword finds the keyword s stands for, tolerating a single typo in
+// keywords long enough that this doesn't make them ambiguous.
+func matchKeyword(s string) (kw string, exact bool) {
+	s = strings.ToLower(s)
+	if _, ok := keywords[s]; ok {
+		return s, true
+	}
+	for _, k := range keywordNames() {
+		if len(k) >= 5 && editDistance(s, k) == 1 {
+			return k, false
+		}
+	}
+	return "", false
+}
+
+func guessKeyword(s string) []Guess {
+	if unanchored() {
+		return nil
+	}
+	if kw, exact := matchKeyword(s); kw != "" {
+		if kw == "now" {
+			return guessTimestamp(now().Unix())
+		}
+		t := keywords[kw](now())
+		var g Guess
+		if kw == "noon" || kw == "midnight" {
+			g = dateGuess(t)
+		} else {
+			g = dayGuess(t)
+		}
+		g.guess = strings.ToUpper(kw[:1]) + kw[1:] + " is " + g.guess
+		g.source = "keyword"
+		g.goodness = 200
+		if !exact {
+			g.comment = strings.TrimSpace(fmt.Sprintf("did you mean %q? %s", kw, g.comment))
+			g.goodness = 150
+		}
+		return []Guess{g}
+	}
+
+	t, ok := parseOffset(s)
+	if !ok {
+		return nil
+	}
+	g := dateGuess(t)
+	g.guess = fmt.Sprintf("Now %s is %s", s, g.guess)
+	g.source = "offset from now"
+	g.goodness = 200
+	return []Guess{g}
+}
+
+// parseOffset parses offsets from now like +3d or -1h30m.
+func parseOffset(s string) (time.Time, bool) {
+	if m := offsetRE.FindStringSubmatch(s); m != nil {
+		days, err := strconv.Atoi(m[2])
+		if err != nil {
+			return time.Time{}, false
+		}
+		if m[3] == "w" {
+			days *= 7
+		}
+		if days == 0 {
+			return time.Time{}, false
+		}
+		if m[1] == "-" {
+			days = -days
+		}
+		return now().AddDate(0, 0, days), true
+	}
+	if len(s) < 2 || s[0] != '+' && s[0] != '-' {
+		return time.Time{}, false
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d == 0 {
+		return time.Time{}, false
+	}
+	return now().Add(d), true
+}
+
+// Units for relative time expressions like "3 days ago".  Months and years
+// have no fixed duration and are handled as calendar arithmetic.
+var relativeUnits = map[string]time.Duration{
+	"second": time.Second,
+	"sec":    time.Second,
+	"minute": time.Minute,
+	"min":    time.Minute,
+	"hour":   time.Hour,
+	"hr":     time.Hour,
+	"day":    24 * time.Hour,
+	"week":   7 * 24 * time.Hour,
+	"month":  0,
+	"year":   0,
+}
+
+// guessRelative understands phrases like "3 days ago", "in 2 weeks" or
+// "an hour and 5 minutes from now".
+func guessRelative(s string) []Guess {
+	words := strings.Fields(strings.ToLower(strings.ReplaceAll(s, ",", " ")))
+	sign := 0
+	switch {
+	case len(words) > 1 && words[0] == "in":
+		sign, words = 1, words[1:]
+	case len(words) > 1 && words[len(words)-1] == "ago":
+		sign, words = -1, words[:len(words)-1]
+	case len(words) > 2 && words[len(words)-2] == "from" && words[len(words)-1] == "now":
+		sign, words = 1, words[:len(words)-2]
+	default:
+		return nil
+	}
+	if unanchored() {
+		trace("leaving out %q relative to now with -stable, see -now", s)
+		return nil
+	}
+
+	var d time.Duration
+	var years, months int
+	pairs := 0
+	for len(words) > 0 {
+		if words[0] == "and" {
+			words = words[1:]
+			continue
+		}
+		if len(words) < 2 {
+			return nil
+		}
+		var n int
+		switch words[0] {
+		case "a", "an", "one":
+			n = 1
+		default:
+			var err error
+			if n, err = strconv.Atoi(words[0]); err != nil || n < 0 {
+				return nil
+			}
+		}
+		unit := strings.TrimSuffix(words[1], "s")
+		u, ok := relativeUnits[unit]
+		if !ok {
+			return nil
+		}
+		switch unit {
+		case "month":
+			months += n
+		case "year":
+			years += n
+		default:
+			d += time.Duration(n) * u
+		}
+		pairs++
+		words = words[2:]
+	}
+	if pairs == 0 {
+		return nil
+	}
+	t := now().AddDate(sign*years, sign*months, 0).Add(time.Duration(sign) * d)
+
+	g := dateGuess(t)
+	g.guess = fmt.Sprintf("%s is %s", s, g.guess)
+	g.source = "relative time expression"
+	g.goodness = 200
+	return []Guess{g}
+}
@@ -0,0 +1,75 @@
+package main
+
+import "testing"
+
+func TestGuessKeyword(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess string
+		good      int
+	}{
+		{"now", "Timestamp 1443346122 is 2015-09-27 09:28:42 +0000 UTC", 200},
+		{"today", "Today is Sunday, 2015-09-27", 200},
+		{"Yesterday", "Yesterday is Saturday, 2015-09-26", 200},
+		{"tomorrow", "Tomorrow is Monday, 2015-09-28", 200},
+		{"tomorow", "Tomorrow is Monday, 2015-09-28", 150},
+		{"yesterdya", "Yesterday is Saturday, 2015-09-26", 150},
+		{"noon", "Noon is 2015-09-27 12:00:00 +0000 UTC", 200},
+		{"midnight", "Midnight is 2015-09-27 00:00:00 +0000 UTC", 200},
+		{"+3d", "Now +3d is 2015-09-30 09:28:42.085 +0000 UTC", 200},
+		{"-1w", "Now -1w is 2015-09-20 09:28:42.085 +0000 UTC", 200},
+		{"-2h", "Now -2h is 2015-09-27 07:28:42.085 +0000 UTC", 200},
+		{"+1h30m", "Now +1h30m is 2015-09-27 10:58:42.085 +0000 UTC", 200},
+	} {
+		gs := guessKeyword(tc.in)
+		if len(gs) == 0 || gs[0].guess != tc.guess || gs[0].goodness != tc.good {
+			t.Errorf("guessKeyword(%q) = %+v, want %q with goodness %d", tc.in, gs, tc.guess, tc.good)
+		}
+	}
+	for _, s := range []string{"nwo", "noo", "3d", "+", "+3y", "-5", "todays are", "+0", "-0", "+0d", "-0w", "+0s", "-0h0m"} {
+		if gs := guessKeyword(s); gs != nil {
+			t.Errorf("guessKeyword(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		d    int
+	}{
+		{"today", "today", 0},
+		{"today", "tday", 1},
+		{"today", "todya", 1},
+		{"today", "toady", 1},
+		{"noon", "moon", 1},
+		{"kitten", "sitting", 3},
+	} {
+		if d := editDistance(tc.a, tc.b); d != tc.d {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tc.a, tc.b, d, tc.d)
+		}
+	}
+}
+
+func TestGuessRelative(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess string
+	}{
+		{"3 days ago", "3 days ago is 2015-09-24 09:28:42.085 +0000 UTC"},
+		{"1 day ago", "1 day ago is 2015-09-26 09:28:42.085 +0000 UTC"},
+		{"in 2 weeks", "in 2 weeks is 2015-10-11 09:28:42.085 +0000 UTC"},
+		{"5 minutes from now", "5 minutes from now is 2015-09-27 09:33:42.085 +0000 UTC"},
+		{"an hour and 30 mins ago", "an hour and 30 mins ago is 2015-09-27 07:58:42.085 +0000 UTC"},
+		{"In 1 year, 2 months", "In 1 year, 2 months is 2016-11-27 09:28:42.085 +0000 UTC"},
+		{"a month ago", "a month ago is 2015-08-27 09:28:42.085 +0000 UTC"},
+	} {
+		gs := guessRelative(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess {
+			t.Errorf("guessRelative(%q) = %+v, want %q", tc.in, gs, tc.guess)
+		}
+	}
+	for _, s := range []string{"ago", "3 days", "in 3", "3 parsecs ago", "in a while", "days ago", "in -3 days", "from now"} {
+		if gs := guessRelative(s); gs != nil {
+			t.Errorf("guessRelative(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+)
+
+// calendarNames holds the localized names used in calendars and dates.
+type calendarNames struct {
+	months [12]string
+	// Weekday names in time.Weekday order, i.e. starting with Sunday,
+	// and their abbreviations for the calendar header.
+	days, abbrevs [7]string
+}
+
+// Locales that -locale supports.  The first one is the fallback.
+var localeTags = []language.Tag{
+	language.English,
+	language.German,
+	language.French,
+	language.Spanish,
+	language.Italian,
+	language.Dutch,
+	language.Portuguese,
+	language.Japanese,
+}
+
+var localeNames = []calendarNames{
+	{
+		[12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		[7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		[7]string{"Su", "Mo", "Tu", "We", "Th", "Fr", "Sa"},
+	},
+	{
+		[12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		[7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		[7]string{"So", "Mo", "Di", "Mi", "Do", "Fr", "Sa"},
+	},
+	{
+		[12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		[7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		[7]string{"di", "lu", "ma", "me", "je", "ve", "sa"},
+	},
+	{
+		[12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		[7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		[7]string{"do", "lu", "ma", "mi", "ju", "vi", "sá"},
+	},
+	{
+		[12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+		[7]string{"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"},
+		[7]string{"do", "lu", "ma", "me", "gi", "ve", "sa"},
+	},
+	{
+		[12]string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+		[7]string{"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"},
+		[7]string{"zo", "ma", "di", "wo", "do", "vr", "za"},
+	},
+	{
+		[12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		[7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+		[7]string{"dom", "seg", "ter", "qua", "qui", "sex", "sáb"},
+	},
+	{
+		[12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		[7]string{"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日"},
+		[7]string{"日", "月", "火", "水", "木", "金", "土"},
+	},
+}
+
+// names are the calendar names for the locale selected with -locale.
+var names = &localeNames[0]
+
+// setLocale selects the names for the given BCP 47 language tag, falling
+// back to English for languages we don't know.
+func setLocale(s string) {
+	names = &localeNames[0]
+	tag, err := language.Parse(s)
+	if err != nil {
+		trace("cannot parse locale %q: %v", s, err)
+		return
+	}
+	_, i, conf := language.NewMatcher(localeTags).Match(tag)
+	if conf == language.No {
+		trace("locale %q is not supported, using English", s)
+		return
+	}
+	if *asciiOnly && !isASCII(strings.Join(localeNames[i].abbrevs[:], "")) {
+		trace("locale %q cannot be shown in ASCII, using English", s)
+		return
+	}
+	names = &localeNames[i]
+}
+
+// displayWidth returns the number of terminal columns s takes up, counting
+// East Asian wide characters as two.
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		switch width.LookupRune(r).Kind() {
+		case width.EastAsianWide, width.EastAsianFullwidth:
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// padRight pads s with spaces to a display width of n columns.
+func padRight(s string, n int) string {
+	if w := displayWidth(s); w < n {
+		return s + strings.Repeat(" ", n-w)
+	}
+	return s
+}
+
+// ASCII replacements for symbols we print, used with -ascii.
+var asciiReplacer = strings.NewReplacer(
+	"°", " deg ",
+	"′", "'",
+	"″", "\"",
+	"µ", "u",
+	"Ω", "Ohm",
+	"×", "x",
+	"’", "'",
+	"–", "-",
+	"—", "--",
+	"…", "...",
+	"π", "pi",
+)
+
+// Removes diacritics, e.g. turning "März" into "Marz".
+var stripMarks = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+// toASCII replaces non-ASCII characters in s by ASCII equivalents where
+// there are any, and by Go-style \u escapes otherwise.
+func toASCII(s string) string {
+	s = asciiReplacer.Replace(s)
+	if t, _, err := transform.String(stripMarks, s); err == nil {
+		s = t
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			b.WriteRune(r)
+		} else {
+			fmt.Fprintf(&b, "\\u%04x", r)
+		}
+	}
+	return b.String()
+}
+
+// isASCII reports whether toASCII can render s without escapes.
+func isASCII(s string) bool {
+	return !strings.Contains(toASCII(s), `\u`)
+}
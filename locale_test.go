@@ -0,0 +1,63 @@
+package main
+
+import "testing"
+
+func TestLocaleCalendar(t *testing.T) {
+	defer setLocale("en")
+	for _, tc := range []struct {
+		locale, caption, header string
+	}{
+		{"de", "   September 2015", "Mo Di Mi Do Fr Sa So"},
+		{"de-AT", "   September 2015", "Mo Di Mi Do Fr Sa So"},
+		{"es", "  septiembre 2015", "lu ma mi ju vi sá do"},
+		{"ja", "      9月 2015", "月 火 水 木 金 土 日"},
+		{"pt-BR", "       setembro 2015", "seg ter qua qui sex sáb dom"},
+		{"tlh", "   September 2015", "Mo Tu We Th Fr Sa Su"},
+		{"not a locale", "   September 2015", "Mo Tu We Th Fr Sa Su"},
+	} {
+		setLocale(tc.locale)
+		cal := calendar(testNow)
+		if cal[0] != tc.caption || cal[1] != tc.header {
+			t.Errorf("calendar for locale %q starts with %q, %q, want %q, %q", tc.locale, cal[0], cal[1], tc.caption, tc.header)
+		}
+	}
+	// Longer abbreviations widen the columns.
+	setLocale("pt")
+	if got, want := calendar(testNow)[2], "      1   2   3   4   5   6"; got != want {
+		t.Errorf("calendar for locale pt has first week %q, want %q", got, want)
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	for _, tc := range []struct {
+		s string
+		w int
+	}{
+		{"Mo", 2},
+		{"sá", 2},
+		{"月", 2},
+		{"9月 2015", 8},
+	} {
+		if w := displayWidth(tc.s); w != tc.w {
+			t.Errorf("displayWidth(%q) = %d, want %d", tc.s, w, tc.w)
+		}
+	}
+	if got := padRight("月", 3); got != "月 " {
+		t.Errorf("padRight() = %q", got)
+	}
+}
+
+func TestToASCII(t *testing.T) {
+	for _, tc := range []struct {
+		in, out string
+	}{
+		{"plain", "plain"},
+		{"März", "Marz"},
+		{"52.5000°N", "52.5000 deg N"},
+		{"⠓", `\u2813`},
+	} {
+		if got := toASCII(tc.in); got != tc.out {
+			t.Errorf("toASCII(%q) = %q, want %q", tc.in, got, tc.out)
+		}
+	}
+}
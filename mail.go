@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Escaped bytes and soft line breaks of quoted-printable text.
+var (
+	qpEscapeRE    = regexp.MustCompile(`=[0-9A-Fa-f]{2}`)
+	qpSoftBreakRE = regexp.MustCompile(`=\r?\n`)
+	encodedWordRE = regexp.MustCompile(`=\?([^?\s]+)\?([BbQq])\?[^?\s]*\?=`)
+)
+
+// latin1 decodes ISO-8859-1, whose bytes are the first 256 code points.
+func latin1(b []byte) string {
+	r := make([]rune, len(b))
+	for i, c := range b {
+		r[i] = rune(c)
+	}
+	return string(r)
+}
+
+// guessQuotedPrintable decodes the MIME quoted-printable encoding of email
+// headers and bodies, like caf=C3=A9.  It needs at least one =XX escape
+// so that text with the odd = sign in it isn't taken for it, and a single
+// one, as in x=10 or caf=E9, is only an unlikely guess.  Text that decodes
+// to control characters isn't quoted-printable.
+func guessQuotedPrintable(s string) []Guess {
+	if encodedWordRE.MatchString(s) {
+		// Left to guessEncodedWords.
+		return nil
+	}
+	escapes := len(qpEscapeRE.FindAllStringIndex(s, -1))
+	if escapes == 0 {
+		return nil
+	}
+	b, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(s)))
+	if err != nil {
+		trace("cannot decode %q as quoted-printable: %v", s, err)
+		return nil
+	}
+	text, comment := string(b), ""
+	if !utf8.Valid(b) {
+		text, comment = latin1(b), "in ISO-8859-1"
+	}
+	if strings.IndexFunc(text, func(r rune) bool { return unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' }) >= 0 {
+		trace("%q decodes to control characters as quoted-printable", s)
+		return nil
+	}
+	g := Guess{
+		guess:    "Quoted-printable text " + text,
+		comment:  comment,
+		source:   "quoted-printable",
+		goodness: -10,
+	}
+	if escapes+len(qpSoftBreakRE.FindAllStringIndex(s, -1)) >= 2 {
+		g.goodness = 100
+	}
+	return []Guess{g}
+}
+
+// guessEncodedWords decodes the RFC 2047 encoded-words of email headers,
+// like =?UTF-8?B?Y2Fmw6k=?= or =?ISO-8859-1?Q?caf=E9?=, along with any
+// plain text between them.
+func guessEncodedWords(s string) []Guess {
+	ms := encodedWordRE.FindAllStringSubmatch(s, -1)
+	if ms == nil {
+		return nil
+	}
+	dec := new(mime.WordDecoder)
+	text, err := dec.DecodeHeader(s)
+	if err != nil {
+		trace("cannot decode encoded-words in %q: %v", s, err)
+		return nil
+	}
+	if text == s {
+		// Malformed encoded-words are left as they are.
+		return nil
+	}
+	var words []string
+	for _, m := range ms {
+		enc := "base64"
+		if strings.EqualFold(m[2], "Q") {
+			enc = "quoted-printable"
+		}
+		words = append(words, fmt.Sprintf("%s in %s", enc, m[1]))
+	}
+	return []Guess{{
+		guess:    "Email header text " + text,
+		comment:  strings.Join(words, ", "),
+		source:   "MIME encoded-word",
+		goodness: 200,
+	}}
+}
@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestGuessQuotedPrintable(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess, comment string
+		goodness           int
+	}{
+		{"caf=C3=A9", "Quoted-printable text café", "", 100},
+		{"caf=E9", "Quoted-printable text café", "in ISO-8859-1", -10},
+		{"=C3=A9", "Quoted-printable text é", "", 100},
+		{"timeout=30", "Quoted-printable text timeout0", "", -10},
+		{"Gr=C3=BC=C3=9Fe aus K=C3=B6ln", "Quoted-printable text Grüße aus Köln", "", 100},
+		{"a long line=\nwrapped=3D", "Quoted-printable text a long linewrapped=", "", 100},
+	} {
+		gs := guessQuotedPrintable(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess || gs[0].comment != tc.comment || gs[0].goodness != tc.goodness {
+			t.Errorf("guessQuotedPrintable(%q) = %+v, want %q (%s) with goodness %d", tc.in, gs, tc.guess, tc.comment, tc.goodness)
+		}
+	}
+	for _, s := range []string{"a=b", "plain text", "x==", "=ZZ", "=?UTF-8?Q?caf=C3=A9?=", "x=10", "a=0D=0A=00"} {
+		if gs := guessQuotedPrintable(s); gs != nil {
+			t.Errorf("guessQuotedPrintable(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessEncodedWords(t *testing.T) {
+	for _, tc := range []struct{ in, guess, comment string }{
+		{"=?UTF-8?B?Y2Fmw6k=?=", "Email header text café", "base64 in UTF-8"},
+		{"=?ISO-8859-1?Q?caf=E9?=", "Email header text café", "quoted-printable in ISO-8859-1"},
+		{"=?utf-8?q?Gr=C3=BC=C3=9Fe_aus_K=C3=B6ln?=", "Email header text Grüße aus Köln", "quoted-printable in utf-8"},
+		{"Re: =?UTF-8?B?Y2Fmw6k=?= =?UTF-8?Q?_au_lait?=", "Email header text Re: café au lait", "base64 in UTF-8, quoted-printable in UTF-8"},
+	} {
+		gs := guessEncodedWords(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess || gs[0].comment != tc.comment || gs[0].goodness != 200 {
+			t.Errorf("guessEncodedWords(%q) = %+v, want %q (%s)", tc.in, gs, tc.guess, tc.comment)
+		}
+	}
+	for _, s := range []string{"caf=C3=A9", "=?UTF-8?X?abc?=", "=?KOI8-R?B?98HT?=", "=?UTF-8?B?!!!?="} {
+		if gs := guessEncodedWords(s); gs != nil {
+			t.Errorf("guessEncodedWords(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// lookupContext returns a context that bounds a DNS lookup by the -timeout
+// flag.  Callers must check -offline themselves before doing any lookups.
+func lookupContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), *netTimeout)
+}
+
+func guessEmail(s string) []Guess {
+	if !strings.Contains(s, "@") {
+		return nil
+	}
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		trace("cannot parse %q as email address: %v", s, err)
+		return nil
+	}
+	at := strings.LastIndex(addr.Address, "@")
+	if at < 0 {
+		return nil
+	}
+	local, domain := addr.Address[:at], addr.Address[at+1:]
+
+	var additional []string
+	if addr.Name != "" {
+		additional = append(additional, fmt.Sprintf("display name: %s", addr.Name))
+	}
+	additional = append(additional, fmt.Sprintf("local part: %s", local))
+	additional = append(additional, fmt.Sprintf("domain: %s", domain))
+
+	if strings.HasPrefix(domain, "[") && strings.HasSuffix(domain, "]") {
+		literal := strings.TrimPrefix(strings.Trim(domain, "[]"), "IPv6:")
+		if ip := net.ParseIP(literal); ip != nil {
+			additional = append(additional, fmt.Sprintf("domain is an IP literal: %s", ip))
+		} else {
+			additional = append(additional, "(domain literal is not a valid IP address)")
+		}
+	} else if !*offline {
+		additional = append(additional, mxInfo(domain)...)
+	}
+
+	return []Guess{{
+		guess:      "Email address " + addr.Address,
+		additional: additional,
+		source:     "email address",
+		goodness:   180,
+	}}
+}
+
+func mxInfo(domain string) []string {
+	ctx, cancel := lookupContext()
+	defer cancel()
+	mxs, err := net.DefaultResolver.LookupMX(ctx, domain)
+	if err != nil || len(mxs) == 0 {
+		trace("MX lookup for %s failed: %v", domain, err)
+		return []string{"(domain has no MX records)"}
+	}
+	var lines []string
+	for _, mx := range mxs {
+		lines = append(lines, fmt.Sprintf("mail exchanger: %s (preference %d)", mx.Host, mx.Pref))
+	}
+	return lines
+}
+
+// ipClass describes what kind of address ip is, e.g. "private" or
+// "loopback".
+func ipClass(ip net.IP) string {
+	switch {
+	case ip.IsLoopback():
+		return "loopback"
+	case ip.IsPrivate():
+		return "private"
+	case ip.IsLinkLocalUnicast(), ip.IsLinkLocalMulticast():
+		return "link-local"
+	case ip.IsMulticast():
+		return "multicast"
+	case ip.IsUnspecified():
+		return "unspecified"
+	case ip.IsGlobalUnicast():
+		return "global unicast"
+	}
+	return "reserved"
+}
+
+// looksLikeHostname reports whether s is a dot-separated sequence of valid
+// DNS labels ending in a TLD known to the public suffix list.
+func looksLikeHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if len(s) > 253 || !strings.Contains(s, ".") {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return false
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	// For TLDs that are not on the list, PublicSuffix falls back to the
+	// last label and reports it as not managed by ICANN.
+	suffix, icann := publicsuffix.PublicSuffix(strings.ToLower(s))
+	return icann || strings.Contains(suffix, ".")
+}
+
+func guessDomain(s string) []Guess {
+	if !looksLikeHostname(s) {
+		return nil
+	}
+	host := strings.ToLower(strings.TrimSuffix(s, "."))
+	var additional []string
+	if etld1, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+		additional = append(additional, fmt.Sprintf("registrable domain: %s", etld1))
+	} else {
+		suffix, _ := publicsuffix.PublicSuffix(host)
+		additional = append(additional, fmt.Sprintf("public suffix: %s", suffix))
+	}
+
+	good := 120
+	if !*offline {
+		ctx, cancel := lookupContext()
+		defer cancel()
+		ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil || len(ips) == 0 {
+			trace("cannot resolve %s: %v", host, err)
+			additional = append(additional, "(host name does not resolve)")
+			good = 50
+		} else {
+			for _, ip := range ips {
+				rr := "A"
+				if ip.IP.To4() == nil {
+					rr = "AAAA"
+				}
+				additional = append(additional, fmt.Sprintf("%s record: %s (%s)", rr, ip.IP, ipClass(ip.IP)))
+			}
+			good = 180
+		}
+	}
+
+	return []Guess{{
+		guess:      "Host name " + host,
+		additional: additional,
+		source:     "domain name",
+		goodness:   good,
+	}}
+}
+
+// guessPackedIP interprets a decimal or 0x-prefixed hex integer as an IPv4
+// address packed into 32 bits, like 3232235521 for 192.168.0.1.  Most
+// decimal integers are something else, timestamps and dates among them,
+// so those are only unlikely guesses, and none of them are worth a
+// reverse lookup.
+func guessPackedIP(s string) []Guess {
+	digits, base := s, 10
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		digits, base = s[2:], 16
+	}
+	if digits == "" || strings.HasPrefix(digits, "+") || strings.HasPrefix(digits, "-") {
+		return nil
+	}
+	n, err := strconv.ParseUint(digits, base, 32)
+	if err != nil {
+		trace("cannot parse %q as packed IPv4 address: %v", s, err)
+		return nil
+	}
+	// Below 1.0.0.0, it's far more likely to be just a number.
+	if n < 1<<24 {
+		return nil
+	}
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, uint32(n))
+	g := ipGuess(ip)
+	g.comment = fmt.Sprintf("packed into integer %s", s)
+	g.source = "packed IP address"
+	g.goodness = -10
+	if base == 16 {
+		g.goodness = 20
+	}
+	return []Guess{g}
+}
+
+// guessHexIP interprets 8 or 32 hex digits without prefix as an IPv4 or
+// IPv6 address in network byte order, as in packet dumps from tcpdump or
+// Wireshark, where they may also be split into groups like c0a8 0001.  As
+// 8 hex digits could be anything, addresses that make sense as such are
+// only moderately likely, and without any of the digits a to f, like the
+// date 20150927, they're unlikely.  Neither is worth a reverse lookup.
+func guessHexIP(s string) []Guess {
+	digits := strings.ReplaceAll(s, " ", "")
+	if len(digits) != 2*net.IPv4len && len(digits) != 2*net.IPv6len || !isHex(digits) {
+		return nil
+	}
+	b, err := hex.DecodeString(digits)
+	if err != nil {
+		return nil
+	}
+	ip := net.IP(b)
+	goodness := 0
+	switch {
+	case len(ip) == net.IPv4len && ip[0] != 0 && ip[0] < 240:
+		goodness = 50
+	case len(ip) == net.IPv6len && (ip.IsGlobalUnicast() || ip.IsLinkLocalUnicast() || ip.IsLoopback() || ip.IsMulticast()) && ip.To4() == nil:
+		goodness = 100
+	}
+	if !strings.ContainsAny(strings.ToLower(digits), "abcdef") {
+		goodness = -10
+	}
+	g := ipGuess(ip)
+	g.comment = fmt.Sprintf("from hex %s", s)
+	g.source = "hex-encoded IP address"
+	g.goodness = goodness
+	return []Guess{g}
+}
+
+// asnClass describes the range an autonomous system number falls into,
+// see https://www.iana.org/assignments/as-numbers/.
+func asnClass(n uint64) string {
+	switch {
+	case n == 0, n == 65535, n == 4294967295:
+		return "reserved"
+	case n == 23456:
+		return "AS_TRANS, stands in for 32-bit ASNs"
+	case n >= 64496 && n <= 64511, n >= 65536 && n <= 65551:
+		return "reserved for documentation"
+	case n >= 64512 && n <= 65534, n >= 4200000000:
+		return "private use"
+	}
+	return "public"
+}
+
+// asnOrganization looks up who an ASN is registered to using Team Cymru's
+// DNS service, whose answers look like
+// "15169 | US | arin | 2000-03-30 | GOOGLE - Google LLC, US".
+func asnOrganization(n uint64) string {
+	ctx, cancel := lookupContext()
+	defer cancel()
+	txts, err := net.DefaultResolver.LookupTXT(ctx, fmt.Sprintf("AS%d.asn.cymru.com", n))
+	if err != nil || len(txts) == 0 {
+		trace("ASN lookup for %d failed: %v", n, err)
+		return ""
+	}
+	fields := strings.Split(txts[0], "|")
+	return strings.TrimSpace(fields[len(fields)-1])
+}
+
+// guessASN recognizes autonomous system numbers like AS15169, in asplain
+// or asdot (AS1.10) notation.  Bare integers could be anything, so they
+// are only unlikely guesses.
+func guessASN(s string) []Guess {
+	num := s
+	prefixed := len(s) > 2 && strings.EqualFold(s[:2], "AS")
+	if prefixed {
+		num = strings.TrimPrefix(s[2:], " ")
+	}
+	if num == "" || num[0] < '0' || num[0] > '9' {
+		return nil
+	}
+	var n uint64
+	var err error
+	if hi, lo, dot := strings.Cut(num, "."); dot && prefixed {
+		var h, l uint64
+		if h, err = strconv.ParseUint(hi, 10, 16); err == nil {
+			l, err = strconv.ParseUint(lo, 10, 16)
+		}
+		n = h<<16 | l
+	} else {
+		n, err = strconv.ParseUint(num, 10, 32)
+	}
+	if err != nil {
+		trace("cannot parse %q as ASN: %v", s, err)
+		return nil
+	}
+	bits := "32-bit"
+	if n <= 65535 {
+		bits = "16-bit"
+	}
+	g := Guess{
+		guess:    fmt.Sprintf("Autonomous system number AS%d", n),
+		comment:  fmt.Sprintf("%s, %s", bits, asnClass(n)),
+		source:   "AS number",
+		goodness: 180,
+	}
+	if n > 65535 {
+		g.additional = append(g.additional, fmt.Sprintf("asdot: AS%d.%d", n>>16, n&0xffff))
+	}
+	if !prefixed {
+		g.goodness = -10
+		return []Guess{g}
+	}
+	if !*offline && asnClass(n) == "public" {
+		if org := asnOrganization(n); org != "" {
+			g.additional = append(g.additional, "registered to: "+org)
+		}
+	}
+	return []Guess{g}
+}
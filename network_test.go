@@ -0,0 +1,138 @@
+package main
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestIPClass(t *testing.T) {
+	for _, tc := range []struct {
+		ip, want string
+	}{
+		{"127.0.0.1", "loopback"},
+		{"::1", "loopback"},
+		{"192.168.0.1", "private"},
+		{"fd00::1", "private"},
+		{"169.254.1.1", "link-local"},
+		{"239.1.2.3", "multicast"},
+		{"0.0.0.0", "unspecified"},
+		{"8.8.8.8", "global unicast"},
+	} {
+		if got := ipClass(net.ParseIP(tc.ip)); got != tc.want {
+			t.Errorf("ipClass(%s) = %q, want %q", tc.ip, got, tc.want)
+		}
+	}
+}
+
+func TestGuessEmail(t *testing.T) {
+	gs := guessEmail("Joe <joe@example.com>")
+	if len(gs) != 1 {
+		t.Fatalf("guessEmail() = %+v, want one guess", gs)
+	}
+	want := []string{"display name: Joe", "local part: joe", "domain: example.com"}
+	if g := gs[0]; g.guess != "Email address joe@example.com" || !reflect.DeepEqual(g.additional, want) {
+		t.Errorf("guessEmail() = %+v", g)
+	}
+	for _, s := range []string{"example.com", "@", "a@b@c"} {
+		if gs := guessEmail(s); gs != nil {
+			t.Errorf("guessEmail(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessDomain(t *testing.T) {
+	for _, tc := range []struct {
+		in, registrable string
+	}{
+		{"www.example.co.uk", "registrable domain: example.co.uk"},
+		{"foo.bar.github.io", "registrable domain: bar.github.io"},
+		{"Example.COM.", "registrable domain: example.com"},
+		{"co.uk", "public suffix: co.uk"},
+	} {
+		gs := guessDomain(tc.in)
+		if len(gs) != 1 || gs[0].additional[0] != tc.registrable {
+			t.Errorf("guessDomain(%q) = %+v, want %q", tc.in, gs, tc.registrable)
+		}
+	}
+	for _, s := range []string{"file.txt", "1.5", "127.0.0.1", "localhost", "-a.com", "a..com", "joe@example.com"} {
+		if gs := guessDomain(s); gs != nil {
+			t.Errorf("guessDomain(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessPackedIP(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+		goodness int
+	}{
+		{"3232235521", "IP address 192.168.0.1", -10},
+		{"0xC0A80001", "IP address 192.168.0.1", 20},
+		{"0x08080808", "IP address 8.8.8.8", 20},
+		{"4294967295", "IP address 255.255.255.255", -10},
+	} {
+		gs := guessPackedIP(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.want || gs[0].goodness != tc.goodness {
+			t.Errorf("guessPackedIP(%q) = %+v, want %q with goodness %d", tc.in, gs, tc.want, tc.goodness)
+		}
+	}
+	for _, s := range []string{"4294967296", "65535", "0x", "-3232235521", "+3232235521", "0o777777777", "1.2.3.4"} {
+		if gs := guessPackedIP(s); gs != nil {
+			t.Errorf("guessPackedIP(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessASN(t *testing.T) {
+	for _, tc := range []struct {
+		in, comment string
+		goodness    int
+	}{
+		{"AS15169", "16-bit, public", 180},
+		{"as64512", "16-bit, private use", 180},
+		{"AS 23456", "16-bit, AS_TRANS, stands in for 32-bit ASNs", 180},
+		{"AS1.10", "32-bit, reserved for documentation", 180},
+		{"AS4200000001", "32-bit, private use", 180},
+		{"15169", "16-bit, public", -10},
+	} {
+		gs := guessASN(tc.in)
+		if len(gs) != 1 || gs[0].comment != tc.comment || gs[0].goodness != tc.goodness {
+			t.Errorf("guessASN(%q) = %+v, want %q with goodness %d", tc.in, gs, tc.comment, tc.goodness)
+		}
+	}
+	if gs := guessASN("AS1.10"); len(gs) == 1 && gs[0].guess != "Autonomous system number AS65546" {
+		t.Errorf("guessASN(AS1.10) = %q", gs[0].guess)
+	}
+	for _, s := range []string{"AS", "ASX", "AS4294967296", "1.10", "AS-1", "ASIA"} {
+		if gs := guessASN(s); gs != nil {
+			t.Errorf("guessASN(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessHexIP(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess string
+		goodness  int
+	}{
+		{"c0a80001", "IP address 192.168.0.1", 50},
+		{"c0a8 0001", "IP address 192.168.0.1", 50},
+		{"20010db8000000000000000000000001", "IP address 2001:db8::1", 100},
+		{"fe80 0000 0000 0000 0202 b3ff fe1e 8329", "IP address fe80::202:b3ff:fe1e:8329", 100},
+		{"00000001", "IP address 0.0.0.1", -10},
+		{"20150927", "IP address 32.21.9.39", -10},
+		{"ffffffff", "IP address 255.255.255.255", 0},
+		{"00000000000000000000000000000000", "IP address ::", -10},
+	} {
+		gs := guessHexIP(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess || gs[0].goodness != tc.goodness {
+			t.Errorf("guessHexIP(%q) = %+v, want %q with goodness %d", tc.in, gs, tc.guess, tc.goodness)
+		}
+	}
+	for _, s := range []string{"c0a800", "0xc0a80001", "c0a8000g", "3390ae5"} {
+		if gs := guessHexIP(s); gs != nil {
+			t.Errorf("guessHexIP(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// jsonSchemaVersion is the version of the -json and -json-stream output
+// format.  Bump it whenever a field is renamed, removed or changes its
+// meaning, so that downstream tools can detect the change.  Adding new
+// fields does not require a new version.
+const jsonSchemaVersion = 1
+
+// jsonGuess is the JSON representation of a single Guess.
+type jsonGuess struct {
+	Guess      string   `json:"guess"`
+	Comment    string   `json:"comment,omitempty"`
+	Additional []string `json:"additional,omitempty"`
+	Source     string   `json:"source"`
+	Goodness   int      `json:"goodness"`
+	Confidence string   `json:"confidence"`
+}
+
+// jsonReport is what -json prints: all guesses for one input.
+type jsonReport struct {
+	SchemaVersion int         `json:"schema_version"`
+	Input         string      `json:"input"`
+	Guesses       []jsonGuess `json:"guesses"`
+}
+
+// jsonStreamGuess is what -json-stream prints, one line per guess.
+type jsonStreamGuess struct {
+	SchemaVersion int    `json:"schema_version"`
+	Input         string `json:"input"`
+	jsonGuess
+}
+
+// confidence maps goodness onto a coarse, stable tier that is easier to
+// depend on than the raw numbers, which get tuned all the time.
+func confidence(goodness int) string {
+	switch {
+	case goodness >= 150:
+		return "high"
+	case goodness >= 50:
+		return "medium"
+	case goodness >= 0:
+		return "low"
+	}
+	return "unlikely"
+}
+
+func (g *Guess) toJSON() jsonGuess {
+	return jsonGuess{
+		Guess:      g.guess,
+		Comment:    g.comment,
+		Additional: g.additional,
+		Source:     g.source,
+		Goodness:   g.goodness,
+		Confidence: confidence(g.goodness),
+	}
+}
+
+func writeJSON(w io.Writer, input string, gs []Guess) error {
+	r := jsonReport{
+		SchemaVersion: jsonSchemaVersion,
+		Input:         input,
+		Guesses:       []jsonGuess{},
+	}
+	for _, g := range gs {
+		r.Guesses = append(r.Guesses, g.toJSON())
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func writeJSONStream(w io.Writer, input string, gs []Guess) error {
+	enc := json.NewEncoder(w)
+	for _, g := range gs {
+		err := enc.Encode(jsonStreamGuess{
+			SchemaVersion: jsonSchemaVersion,
+			Input:         input,
+			jsonGuess:     g.toJSON(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shownGuesses returns the likely guesses, or all of them if there are no
+// likely ones or -unlikely is given.
+func shownGuesses(gs []Guess) []Guess {
+	if *printUnlikely || !hasLikely(gs) {
+		return gs
+	}
+	var shown []Guess
+	for _, g := range gs {
+		if g.goodness >= 0 {
+			shown = append(shown, g)
+		}
+	}
+	return shown
+}
+
+// writeTable prints one line per guess in aligned columns, leaving out the
+// additional lines, for scanning many guesses at once.  Like printGuesses,
+// it only shows unlikely guesses if there are no others or -unlikely is
+// given.  It returns false if there was nothing to print.
+func writeTable(w io.Writer, gs []Guess) bool {
+	if gs == nil {
+		fmt.Fprintln(w, "Could not guess anything.")
+		return false
+	}
+	shown := shownGuesses(gs)
+	// No colors: tabwriter would count the escape sequences as text.
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "SOURCE\tCONFIDENCE\tGUESS")
+	for _, g := range shown {
+		c := confidence(g.goodness)
+		if *goodnessCap {
+			c = fmt.Sprintf("%s (%d%%)", c, g.percent)
+		}
+		t := g.guess
+		if g.comment != "" {
+			t += " (" + g.comment + ")"
+		}
+		line := g.source + "\t" + c + "\t" + t
+		if *asciiOnly {
+			line = toASCII(line)
+		}
+		fmt.Fprintln(tw, line)
+	}
+	tw.Flush()
+	return true
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`,
+)
+
+// writeMarkdown prints each guess as a paragraph for pasting into issues
+// and docs: the guess in bold, then the comment, then the additional lines
+// in a fenced code block, which keeps calendars aligned.  It returns false
+// if there was nothing to print.
+func writeMarkdown(w io.Writer, gs []Guess) bool {
+	if gs == nil {
+		fmt.Fprintln(w, "Could not guess anything.")
+		return false
+	}
+	for i, g := range shownGuesses(gs) {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "**%s**", markdownEscaper.Replace(plainText(g.guess)))
+		if g.comment != "" {
+			fmt.Fprintf(w, " (%s)", markdownEscaper.Replace(plainText(g.comment)))
+		}
+		fmt.Fprintln(w)
+		if *verbose {
+			fmt.Fprintf(w, "\n_goodness: %d, source: %s_\n", g.goodness, markdownEscaper.Replace(g.source))
+		}
+		if len(g.additional) > 0 {
+			var lines []string
+			for _, l := range g.additional {
+				lines = append(lines, plainText(l))
+			}
+			block := strings.Join(lines, "\n")
+			fence := "```"
+			for strings.Contains(block, fence) {
+				fence += "`"
+			}
+			fmt.Fprintf(w, "\n%s\n%s\n%s\n", fence, block, fence)
+		}
+	}
+	return true
+}
+
+// plainText removes color escape sequences and, with -ascii, anything
+// else that isn't ASCII.
+func plainText(s string) string {
+	s = sgrRE.ReplaceAllString(s, "")
+	if *asciiOnly {
+		return toASCII(s)
+	}
+	return s
+}
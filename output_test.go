@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// If this test fails, you changed the JSON output format.  Make sure that
+// was deliberate, bump jsonSchemaVersion if existing fields changed, and
+// update the expectations here.
+func TestJSONSchemaVersion(t *testing.T) {
+	if jsonSchemaVersion != 1 {
+		t.Errorf("jsonSchemaVersion = %d, want 1", jsonSchemaVersion)
+	}
+
+	gs := []Guess{{
+		guess:      "IP address 127.0.0.1",
+		comment:    "comment",
+		additional: []string{"address class: loopback"},
+		source:     "IP address",
+		goodness:   200,
+	}}
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, "127.0.0.1", gs); err != nil {
+		t.Fatal(err)
+	}
+	var report map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := keys(report), []string{"guesses", "input", "schema_version"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("report fields = %v, want %v", got, want)
+	}
+	guess := report["guesses"].([]interface{})[0].(map[string]interface{})
+	if got, want := keys(guess), []string{"additional", "comment", "confidence", "goodness", "guess", "source"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("guess fields = %v, want %v", got, want)
+	}
+
+	buf.Reset()
+	if err := writeJSONStream(&buf, "127.0.0.1", gs); err != nil {
+		t.Fatal(err)
+	}
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := keys(line), []string{"additional", "comment", "confidence", "goodness", "guess", "input", "schema_version", "source"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("stream fields = %v, want %v", got, want)
+	}
+	if v := line["schema_version"].(float64); v != jsonSchemaVersion {
+		t.Errorf("schema_version = %v, want %d", v, jsonSchemaVersion)
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	gs := []Guess{
+		{guess: "IP address 127.0.0.1", additional: []string{"address class: loopback"}, source: "IP address", goodness: 200},
+		{guess: "Text 127.0.0.1", source: "plain text", goodness: -10},
+		{guess: "Version 127", comment: "or so", source: "version", goodness: 20},
+	}
+	var buf bytes.Buffer
+	if !writeTable(&buf, gs) {
+		t.Fatal("writeTable() = false")
+	}
+	want := "SOURCE      CONFIDENCE  GUESS\n" +
+		"IP address  high        IP address 127.0.0.1\n" +
+		"version     low         Version 127 (or so)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writeTable() printed\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	writeTable(&buf, gs[1:2])
+	if want := "SOURCE      CONFIDENCE  GUESS\nplain text  unlikely    Text 127.0.0.1\n"; buf.String() != want {
+		t.Errorf("writeTable() with only unlikely guesses printed\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	gs := []Guess{
+		{guess: "IP address \x1b[1m127.0.0.1\x1b[0m", additional: []string{"address class: loopback", "```"}, source: "IP address", goodness: 200},
+		{guess: "Text 127.0.0.1", source: "plain text", goodness: -10},
+		{guess: "Version *127*", comment: "or so", source: "version", goodness: 20},
+	}
+	var buf bytes.Buffer
+	if !writeMarkdown(&buf, gs) {
+		t.Fatal("writeMarkdown() = false")
+	}
+	want := "**IP address 127.0.0.1**\n" +
+		"\n" +
+		"````\n" +
+		"address class: loopback\n" +
+		"```\n" +
+		"````\n" +
+		"\n" +
+		"**Version \\*127\\*** (or so)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writeMarkdown() printed\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	if writeMarkdown(&buf, nil) {
+		t.Error("writeMarkdown(nil) = true")
+	}
+}
+
+func TestConfidence(t *testing.T) {
+	for _, tc := range []struct {
+		goodness int
+		want     string
+	}{
+		{200, "high"},
+		{150, "high"},
+		{120, "medium"},
+		{0, "low"},
+		{-10, "unlikely"},
+	} {
+		if got := confidence(tc.goodness); got != tc.want {
+			t.Errorf("confidence(%d) = %q, want %q", tc.goodness, got, tc.want)
+		}
+	}
+}
+
+func keys(m map[string]interface{}) []string {
+	var ks []string
+	for k := range m {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestStartPager(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a Unix shell")
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	t.Setenv("PAGER", "cat > "+out+"; echo $LESS >> "+out)
+	t.Setenv("LESS", "")
+	os.Unsetenv("LESS")
+	w, wait, err := startPager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintln(w, "IP address 127.0.0.1")
+	w.Close()
+	if err := wait(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "IP address 127.0.0.1\nFRX\n"; string(got) != want {
+		t.Errorf("pager got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// defaultPluginDir is where plugins are looked for unless -plugins says
+// otherwise, e.g. ~/.config/guess/plugins on Linux.
+func defaultPluginDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "guess", "plugins")
+}
+
+// plugins lists the executables in dir, sorted by name.
+func plugins(dir string) []string {
+	if dir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		trace("no plugins: %v", err)
+		return nil
+	}
+	var ps []string
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		ps = append(ps, filepath.Join(dir, e.Name()))
+	}
+	return ps
+}
+
+// runPlugin runs the plugin at path with s as its only argument.  The
+// plugin prints a JSON array of guesses using the same fields as -json,
+// without confidence:
+//
+//	[{"guess": "...", "comment": "...", "additional": ["..."], "source": "...", "goodness": 100}]
+//
+// Only guess is required; source defaults to the plugin's file name.  A
+// plugin that has nothing to say prints [] or nothing at all.
+func runPlugin(path, s string, timeout time.Duration) ([]Guess, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path, s)
+	cmd.Stderr = os.Stderr
+	// Don't wait for grandchildren still holding on to stdout.
+	cmd.WaitDelay = 100 * time.Millisecond
+	out, err := cmd.Output()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("timed out after %v", timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	var jgs []jsonGuess
+	if err := json.Unmarshal(out, &jgs); err != nil {
+		return nil, fmt.Errorf("invalid output: %v", err)
+	}
+	var gs []Guess
+	for _, jg := range jgs {
+		if jg.Guess == "" {
+			return nil, fmt.Errorf("invalid output: guess without \"guess\"")
+		}
+		g := Guess{
+			guess:      jg.Guess,
+			comment:    jg.Comment,
+			additional: jg.Additional,
+			source:     jg.Source,
+			goodness:   jg.Goodness,
+		}
+		if g.source == "" {
+			g.source = "plugin " + filepath.Base(path)
+		}
+		gs = append(gs, g)
+	}
+	return gs, nil
+}
+
+// guessPlugins runs all plugins in -plugins concurrently.  Plugins that
+// fail or time out are reported and otherwise ignored.
+func guessPlugins(s string) []Guess {
+	ps := plugins(*pluginDir)
+	results := make([][]Guess, len(ps))
+	var wg sync.WaitGroup
+	for i, p := range ps {
+		wg.Add(1)
+		go func(i int, p string) {
+			defer wg.Done()
+			gs, err := runPlugin(p, s, *pluginTimeout)
+			if err != nil {
+				log.Printf("Plugin %s: %v", filepath.Base(p), err)
+				return
+			}
+			results[i] = gs
+		}(i, p)
+	}
+	wg.Wait()
+	var g []Guess
+	for _, gs := range results {
+		g = append(g, gs...)
+	}
+	return g
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func writePlugin(t *testing.T, dir, name, script string, mode os.FileMode) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGuessPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins in this test are shell scripts")
+	}
+	dir := t.TempDir()
+	writePlugin(t, dir, "a-good", `echo '[{"guess": "answer '"$1"'", "goodness": 42}]'`, 0755)
+	writePlugin(t, dir, "b-silent", `exit 0`, 0755)
+	writePlugin(t, dir, "c-broken", `echo 'not json'`, 0755)
+	writePlugin(t, dir, "d-failing", `exit 3`, 0755)
+	writePlugin(t, dir, "e-slow", `exec sleep 5`, 0755)
+	writePlugin(t, dir, "f-sourced", `echo '[{"guess": "x", "source": "mine"}]'`, 0755)
+	writePlugin(t, dir, "g-not-executable", `echo '[{"guess": "no"}]'`, 0644)
+	setFlag(t, "plugins", dir)
+	setFlag(t, "plugin-timeout", "200ms")
+	// The failing plugins are logged, which is just noise here.
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	start := time.Now()
+	gs := guessPlugins("42")
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("guessPlugins took %v, slow plugin was not stopped", d)
+	}
+	if len(gs) != 2 {
+		t.Fatalf("guessPlugins() = %+v, want 2 guesses", gs)
+	}
+	if g := gs[0]; g.guess != "answer 42" || g.goodness != 42 || g.source != "plugin a-good" {
+		t.Errorf("guessPlugins()[0] = %+v", g)
+	}
+	if g := gs[1]; g.guess != "x" || g.source != "mine" {
+		t.Errorf("guessPlugins()[1] = %+v", g)
+	}
+}
+
+func TestGuessPluginsMissingDir(t *testing.T) {
+	setFlag(t, "plugins", filepath.Join(t.TempDir(), "nonexistent"))
+	if gs := guessPlugins("42"); gs != nil {
+		t.Errorf("guessPlugins() = %+v, want nil", gs)
+	}
+}
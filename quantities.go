@@ -0,0 +1,556 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"guess/bytesize"
+)
+
+// Suffixes of Kubernetes resource quantities, see
+// https://kubernetes.io/docs/reference/kubernetes-api/common-definitions/quantity/
+var k8sSuffixes = []struct {
+	sym    string
+	mult   float64
+	binary bool
+}{
+	{"Ki", 1 << 10, true},
+	{"Mi", 1 << 20, true},
+	{"Gi", 1 << 30, true},
+	{"Ti", 1 << 40, true},
+	{"Pi", 1 << 50, true},
+	{"Ei", 1 << 60, true},
+	{"n", 1e-9, false},
+	{"u", 1e-6, false},
+	{"m", 1e-3, false},
+	{"k", 1e3, false},
+	{"M", 1e6, false},
+	{"G", 1e9, false},
+	{"T", 1e12, false},
+	{"P", 1e15, false},
+	{"E", 1e18, false},
+}
+
+func guessK8sQuantity(s string) []Guess {
+	for _, suf := range k8sSuffixes {
+		if !strings.HasSuffix(s, suf.sym) {
+			continue
+		}
+		num := strings.TrimSuffix(s, suf.sym)
+		if num == "" || strings.ContainsAny(num, "eE ") {
+			return nil
+		}
+		f, err := strconv.ParseFloat(num, 64)
+		if err != nil || f < 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+			trace("cannot parse %q as Kubernetes quantity: %v", s, err)
+			return nil
+		}
+		v := f * suf.mult
+		if suf.mult < 1 {
+			g := Guess{
+				guess:      fmt.Sprintf("%s CPU cores", strconv.FormatFloat(v, 'f', -1, 64)),
+				comment:    fmt.Sprintf("Kubernetes CPU quantity %s", s),
+				additional: []string{fmt.Sprintf("%s millicores", strconv.FormatFloat(v*1000, 'f', -1, 64))},
+				source:     "Kubernetes CPU quantity",
+				goodness:   100,
+			}
+			if suf.sym == "m" && f < 100 {
+				// Few CPU limits are that small, while 5m is more
+				// likely to be five minutes.
+				g.goodness = 50
+			}
+			return []Guess{g}
+		}
+		// Kubernetes rounds fractional byte counts up.
+		bytes := math.Ceil(v)
+		if bytes >= math.MaxInt64 {
+			trace("%q is too many bytes", s)
+			return nil
+		}
+		mem := Guess{
+			guess:      fmt.Sprintf("%.0f bytes", bytes),
+			comment:    fmt.Sprintf("Kubernetes memory quantity %s", s),
+			additional: bytesInfo(int64(bytes)),
+			source:     "Kubernetes memory quantity",
+			goodness:   60,
+		}
+		if suf.binary {
+			return []Guess{mem}
+		}
+		mem.additional = append(mem.additional, fmt.Sprintf("note: %s is a decimal suffix, use %si for powers of 1024", suf.sym, strings.ToUpper(suf.sym)))
+		mem.goodness = 40
+		// Decimal suffixes are valid for CPU too, if rarely sensible.
+		cpu := Guess{
+			guess:    fmt.Sprintf("%s CPU cores", strconv.FormatFloat(v, 'f', -1, 64)),
+			comment:  fmt.Sprintf("Kubernetes CPU quantity %s", s),
+			source:   "Kubernetes CPU quantity",
+			goodness: -10,
+		}
+		return []Guess{mem, cpu}
+	}
+	return nil
+}
+
+// Common meanings of small integers in config dumps and query strings.
+var flagValues = map[string]struct {
+	boolean string
+	enum    []string
+}{
+	"0":  {"false", []string{"off", "disabled", "equal (comparison result)"}},
+	"1":  {"true", []string{"on", "enabled", "greater (comparison result)"}},
+	"-1": {"", []string{"unset, auto or default", "less (comparison result)", "true in some languages, e.g. VB"}},
+}
+
+// guessFlagValue interprets 0, 1 and -1 as booleans and tri-state enums.
+// As almost every small integer is something else as well, this is only
+// done with -flags.
+func guessFlagValue(s string) []Guess {
+	if !*flagsMode {
+		return nil
+	}
+	v, ok := flagValues[s]
+	if !ok {
+		return nil
+	}
+	g := Guess{
+		comment:    fmt.Sprintf("Flag value %s", s),
+		additional: v.enum,
+		source:     "flag value",
+		goodness:   10,
+	}
+	if v.boolean != "" {
+		g.guess = "boolean " + v.boolean
+	} else {
+		g.guess = "tri-state " + v.enum[0]
+		g.additional = v.enum[1:]
+	}
+	return []Guess{g}
+}
+
+// CSS length units in CSS pixels, assuming 96 pixels per inch as CSS does.
+// Font-relative units are relative to -root-px; for em that's only right
+// if the parent element has the root font size.  Physical units are common
+// outside of CSS, so they score lower.
+var cssUnits = []struct {
+	sym      string
+	px       func() float64
+	goodness int
+}{
+	{"px", func() float64 { return 1 }, 150},
+	{"rem", func() float64 { return *rootPx }, 150},
+	{"em", func() float64 { return *rootPx }, 120},
+	{"pt", func() float64 { return 96.0 / 72 }, 100},
+	{"pc", func() float64 { return 16 }, 50},
+	{"in", func() float64 { return 96 }, 50},
+	{"cm", func() float64 { return 96 / 2.54 }, 50},
+	{"mm", func() float64 { return 96 / 25.4 }, 50},
+	{"Q", func() float64 { return 96 / 101.6 }, 20},
+}
+
+func formatCSS(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10000)/10000, 'f', -1, 64)
+}
+
+// guessCSSUnit converts CSS lengths like 16px or 1.5rem to pixels and
+// other units.
+func guessCSSUnit(s string) []Guess {
+	for _, u := range cssUnits {
+		num := strings.TrimSuffix(s, u.sym)
+		if num == s || num == "" || strings.ContainsAny(num, "eE+ ") {
+			continue
+		}
+		f, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			trace("cannot parse %q as CSS length: %v", s, err)
+			return nil
+		}
+		px := f * u.px()
+		var lines []string
+		for _, o := range cssUnits {
+			if o.sym == u.sym || o.sym == "em" || o.sym == "Q" {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%s%s", formatCSS(px/o.px()), o.sym))
+		}
+		lines = append(lines, fmt.Sprintf("assuming 96 dpi and %spx root font size", formatCSS(*rootPx)))
+		return []Guess{{
+			guess:      fmt.Sprintf("%s CSS pixels", formatCSS(px)),
+			comment:    fmt.Sprintf("CSS length %s", s),
+			additional: lines,
+			source:     "CSS length",
+			goodness:   u.goodness,
+		}}
+	}
+	return nil
+}
+
+// guessUnixID describes the conventional meaning of an integer as user or
+// group ID, and who it is on this machine.  Like -flags, this is only done
+// with -ids, as small integers are usually something else.
+func guessUnixID(s string) []Guess {
+	if !*idsMode {
+		return nil
+	}
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return nil
+	}
+	var meaning string
+	switch {
+	case n == 0:
+		meaning = "root"
+	case n < 100:
+		meaning = "system account, statically allocated"
+	case n < 1000:
+		meaning = "system account, dynamically allocated (or a regular user from 500 on some systems)"
+	case n < 60000:
+		meaning = "regular user or group"
+	case n == 65534:
+		meaning = "nobody/nogroup"
+	case n == 65535, n == 4294967295:
+		meaning = "invalid, -1 as unsigned 16- or 32-bit integer"
+	case n < 65536:
+		meaning = "reserved for system use"
+	default:
+		meaning = "regular user or group, e.g. from a directory service or user namespace"
+	}
+	var lines []string
+	if u, err := user.LookupId(s); err == nil {
+		lines = append(lines, "user on this machine: "+u.Username)
+	}
+	if g, err := user.LookupGroupId(s); err == nil {
+		lines = append(lines, "group on this machine: "+g.Name)
+	}
+	return []Guess{{
+		guess:      fmt.Sprintf("User or group ID %d", n),
+		comment:    meaning,
+		additional: lines,
+		source:     "Unix user/group ID",
+		goodness:   10,
+	}}
+}
+
+// SI suffixes people use for plain counts, as in 1.5k requests or 2M rows.
+var countSuffixes = []struct {
+	sym  string
+	mult int64
+	name string
+}{
+	{"k", 1e3, "thousand"},
+	{"K", 1e3, "thousand"},
+	{"M", 1e6, "million"},
+	{"G", 1e9, "billion"},
+	{"T", 1e12, "trillion"},
+}
+
+// groupDigits formats n with commas between groups of three digits.
+func groupDigits(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+// guessCount expands counts with an SI suffix like 1.5k or 2M, and also
+// tries the expanded number as a timestamp.  Without a B these aren't byte
+// counts, and a lowercase k never is, so that one is the most likely.
+func guessCount(s string) []Guess {
+	for _, suf := range countSuffixes {
+		num := strings.TrimSuffix(s, suf.sym)
+		if num == s || num == "" || strings.ContainsAny(num, "eE/ ") {
+			continue
+		}
+		r, ok := new(big.Rat).SetString(num)
+		if !ok {
+			return nil
+		}
+		r.Mul(r, new(big.Rat).SetInt64(suf.mult))
+		if !r.IsInt() || !r.Num().IsInt64() {
+			return nil
+		}
+		n := r.Num().Int64()
+		g := Guess{
+			guess:    fmt.Sprintf("Count %s", groupDigits(n)),
+			comment:  fmt.Sprintf("%s %s", num, suf.name),
+			source:   "count with SI suffix",
+			goodness: 100,
+		}
+		if suf.sym == "k" {
+			g.goodness = 150
+		}
+		gs := []Guess{g}
+		for _, tg := range guessTimestamp(n) {
+			if tg.comment != "" {
+				tg.comment += ", "
+			}
+			tg.comment += "from " + s
+			tg.source += " with SI suffix"
+			tg.goodness -= 50
+			gs = append(gs, tg)
+		}
+		return gs
+	}
+	return nil
+}
+
+// SI prefixes, smallest first.  u is the ASCII spelling of µ, and both the
+// micro sign and the Greek letter mu are in use for µ.
+var siPrefixes = []struct {
+	sym string
+	exp int
+}{
+	{"a", -18}, {"f", -15}, {"p", -12}, {"n", -9},
+	{"µ", -6}, {"μ", -6}, {"u", -6}, {"m", -3},
+	{"", 0},
+	{"k", 3}, {"M", 6}, {"G", 9}, {"T", 12}, {"P", 15}, {"E", 18},
+}
+
+// Physical units that SI prefixes go with.  Longer symbols come first, so
+// that e.g. the unit of 1 mWh isn't taken to be a W.
+var siUnits = []struct {
+	sym, name, quantity string
+}{
+	{"ohm", "Ω", "resistance"},
+	{"Hz", "Hz", "frequency"},
+	{"Wh", "Wh", "energy"},
+	{"eV", "eV", "energy"},
+	{"Pa", "Pa", "pressure"},
+	{"Ω", "Ω", "resistance"},
+	{"F", "F", "capacitance"},
+	{"H", "H", "inductance"},
+	{"V", "V", "voltage"},
+	{"A", "A", "current"},
+	{"W", "W", "power"},
+	{"J", "J", "energy"},
+	{"N", "N", "force"},
+	{"C", "C", "electric charge"},
+	{"S", "S", "conductance"},
+}
+
+// guessSIQuantity recognizes physical quantities like 3.3kΩ, 100nF or
+// 2.4 GHz and shows them in base units and with the most natural prefix,
+// so that 4700pF becomes 4.7 nF.  Only known units are recognized, as a
+// bare prefix like M could mean a lot of things.
+func guessSIQuantity(s string) []Guess {
+	for _, u := range siUnits {
+		rest := strings.TrimSuffix(s, u.sym)
+		if rest == s {
+			continue
+		}
+		for _, p := range siPrefixes {
+			num := strings.TrimSpace(strings.TrimSuffix(rest, p.sym))
+			if p.sym != "" && num == strings.TrimSpace(rest) || num == "" || strings.HasSuffix(num, ".") {
+				continue
+			}
+			f, err := strconv.ParseFloat(num, 64)
+			if err != nil || math.IsInf(f, 0) {
+				continue
+			}
+			v := f * math.Pow10(p.exp)
+			g := Guess{
+				guess:      fmt.Sprintf("%s %s%s", formatSI(v/math.Pow10(naturalExp(v))), naturalPrefix(v), u.name),
+				comment:    u.quantity,
+				additional: []string{fmt.Sprintf("in base units: %s %s", formatSI(v), u.name)},
+				source:     "physical quantity with SI prefix",
+				goodness:   150,
+			}
+			switch {
+			case p.sym == "" && (u.sym == "F" || u.sym == "C"):
+				// Like 98.6F or 100C, far more likely temperatures.
+				g.source = "physical quantity"
+				g.goodness = -10
+			case p.sym == "":
+				g.source = "physical quantity"
+				g.goodness = 100
+			}
+			return []Guess{g}
+		}
+	}
+	return nil
+}
+
+// naturalExp returns the exponent of the SI prefix that puts v between 1
+// and 1000.
+func naturalExp(v float64) int {
+	if v == 0 {
+		return 0
+	}
+	exp := int(math.Floor(math.Log10(math.Abs(v))/3)) * 3
+	if exp < siPrefixes[0].exp {
+		return siPrefixes[0].exp
+	}
+	if last := siPrefixes[len(siPrefixes)-1].exp; exp > last {
+		return last
+	}
+	return exp
+}
+
+func naturalPrefix(v float64) string {
+	exp := naturalExp(v)
+	for _, p := range siPrefixes {
+		if p.exp == exp {
+			return p.sym
+		}
+	}
+	return ""
+}
+
+// formatSI formats v without the noise of binary floating point, e.g.
+// 4.7 rather than 4.699999999999999.
+func formatSI(v float64) string {
+	return strconv.FormatFloat(v, 'g', 12, 64)
+}
+
+// guessThroughput computes transfer rates from a size and a duration, as in
+// 1.5GB in 3s or 100MiB/min.  The size needs a unit; a bare unit like s
+// or h after a slash means one of it.
+func guessThroughput(s string) []Guess {
+	size, dur, ok := strings.Cut(s, " in ")
+	if !ok {
+		size, dur, ok = strings.Cut(s, "/")
+	}
+	if !ok {
+		return nil
+	}
+	size, dur = strings.TrimSpace(size), strings.TrimSpace(dur)
+	if _, err := parseInt(size); err == nil {
+		return nil
+	}
+	n, err := bytesize.ParseBytes(size)
+	if err != nil || n < 0 {
+		return nil
+	}
+	if dur == "min" {
+		dur = "m"
+	}
+	d, err := time.ParseDuration(dur)
+	if err != nil {
+		d, err = time.ParseDuration("1" + dur)
+	}
+	if err != nil || d <= 0 {
+		return nil
+	}
+	rate := float64(n) / d.Seconds()
+	return []Guess{{
+		guess:   "Throughput " + formatSIRate(rate, "B/s"),
+		comment: fmt.Sprintf("%s in %v", size, d),
+		additional: []string{
+			formatBinaryRate(rate),
+			formatSIRate(8*rate, "bit/s"),
+		},
+		source:   "size per duration",
+		goodness: 150,
+	}}
+}
+
+// formatSIRate formats a rate with 4 significant digits and an SI prefix.
+func formatSIRate(v float64, unit string) string {
+	x := v / math.Pow10(naturalExp(v))
+	return fmt.Sprintf("%s %s%s", strconv.FormatFloat(x, 'g', 4, 64), naturalPrefix(v), unit)
+}
+
+// formatBinaryRate formats a rate in bytes per second with binary units.
+func formatBinaryRate(v float64) string {
+	for i := len(bytesize.Units) - 1; i >= 0; i-- {
+		if u := bytesize.Units[i]; v >= float64(u.BinaryMult) {
+			return fmt.Sprintf("%s %s/s", strconv.FormatFloat(v/float64(u.BinaryMult), 'g', 4, 64), u.Binary)
+		}
+	}
+	return strconv.FormatFloat(v, 'g', 4, 64) + " B/s"
+}
+
+// Angle units, in degrees per unit.  grad comes before rad, which it ends
+// with.
+var angleUnits = []struct {
+	suffix, name string
+	degrees      float64
+}{
+	{"°", "°", 1},
+	{"deg", "°", 1},
+	{"grad", " grad", 0.9},
+	{"rad", " rad", 180 / math.Pi},
+	{"gon", " grad", 0.9},
+	{"turn", " turns", 360},
+}
+
+// guessAngle converts angles like 90deg, 1.5708rad or 100grad between
+// degrees, radians, gradians and turns.  A bare ° is only taken if nothing
+// follows it, as 52°31'N is a coordinate.
+func guessAngle(s string) []Guess {
+	for _, u := range angleUnits {
+		num := strings.TrimSpace(strings.TrimSuffix(s, u.suffix))
+		if num == s || num == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(num, 64)
+		if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil
+		}
+		deg := f * u.degrees
+		rad := deg * math.Pi / 180
+		var lines []string
+		for _, v := range []struct {
+			n    float64
+			unit string
+		}{
+			{deg, "°"},
+			{rad, " rad"},
+			{deg / 0.9, " grad"},
+			{deg / 360, " turns"},
+		} {
+			if v.unit == u.name {
+				continue
+			}
+			l := fmt.Sprintf("= %s%s", strconv.FormatFloat(v.n, 'g', 6, 64), v.unit)
+			if frac := piFraction(rad); v.unit == " rad" && frac != "" {
+				l += fmt.Sprintf(" (%s)", frac)
+			}
+			lines = append(lines, l)
+		}
+		g := Guess{
+			guess:      fmt.Sprintf("Angle of %s%s", num, u.name),
+			additional: lines,
+			source:     "angle",
+			goodness:   100,
+		}
+		if frac := piFraction(rad); u.name == " rad" && frac != "" {
+			g.comment = "about " + frac
+		}
+		return []Guess{g}
+	}
+	return nil
+}
+
+// piFraction writes rad as a simple fraction of π like 3π/4, or returns ""
+// if it isn't close to one.
+func piFraction(rad float64) string {
+	for den := 1; den <= 12; den++ {
+		num := math.Round(rad / math.Pi * float64(den))
+		if math.Abs(num*math.Pi/float64(den)-rad) > 1e-4 || num == 0 {
+			continue
+		}
+		s := strconv.Itoa(int(num)) + "π"
+		switch num {
+		case 1:
+			s = "π"
+		case -1:
+			s = "-π"
+		}
+		if den > 1 {
+			s += "/" + strconv.Itoa(den)
+		}
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGuessK8sQuantity(t *testing.T) {
+	for _, tc := range []struct {
+		in      string
+		guesses []string
+	}{
+		{"1500m", []string{"1.5 CPU cores"}},
+		{"250u", []string{"0.00025 CPU cores"}},
+		{"128Mi", []string{"134217728 bytes"}},
+		{"2Gi", []string{"2147483648 bytes"}},
+		{"500k", []string{"500000 bytes", "500000 CPU cores"}},
+		{"1.5Ki", []string{"1536 bytes"}},
+	} {
+		gs := guessK8sQuantity(tc.in)
+		if len(gs) != len(tc.guesses) {
+			t.Errorf("guessK8sQuantity(%q) = %+v, want %q", tc.in, gs, tc.guesses)
+			continue
+		}
+		for i, g := range gs {
+			if g.guess != tc.guesses[i] {
+				t.Errorf("guessK8sQuantity(%q)[%d] = %q, want %q", tc.in, i, g.guess, tc.guesses[i])
+			}
+		}
+	}
+	// 5m is more likely five minutes than a very small CPU limit.
+	if gs, ds := guessK8sQuantity("5m"), guessDuration("5m"); len(gs) != 1 || len(ds) != 1 || gs[0].goodness >= ds[0].goodness {
+		t.Errorf("guessK8sQuantity(5m) = %+v, want it below guessDuration(5m) = %+v", gs, ds)
+	}
+	for _, s := range []string{"m", "Mi", "-1Gi", "1e3m", "12", "abcMi", "Infm", "NaNm", "+Infk"} {
+		if gs := guessK8sQuantity(s); gs != nil {
+			t.Errorf("guessK8sQuantity(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessCount(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess, comment string
+		goodness           int
+	}{
+		{"1.5k", "Count 1,500", "1.5 thousand", 150},
+		{"2M", "Count 2,000,000", "2 million", 100},
+		{"1.443346122G", "Count 1,443,346,122", "1.443346122 billion", 100},
+		{"-3K", "Count -3,000", "-3 thousand", 100},
+	} {
+		gs := guessCount(tc.in)
+		if len(gs) == 0 || gs[0].guess != tc.guess || gs[0].comment != tc.comment || gs[0].goodness != tc.goodness {
+			t.Errorf("guessCount(%q) = %+v, want %q (%s) with goodness %d", tc.in, gs, tc.guess, tc.comment, tc.goodness)
+		}
+	}
+	gs := guessCount("1.443346122G")
+	if len(gs) != 5 || gs[1].guess != "Timestamp 1443346122 is 2015-09-27 09:28:42 +0000 UTC" || gs[1].source != "timestamp (seconds) with SI suffix" {
+		t.Errorf("guessCount(1.443346122G) timestamps = %+v", gs)
+	}
+	for _, s := range []string{"k", "1.2345k", "2MB", "12", "1e3k", "abck", "10000000T"} {
+		if gs := guessCount(s); gs != nil {
+			t.Errorf("guessCount(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessSIQuantity(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess, comment, base string
+	}{
+		{"3.3kΩ", "3.3 kΩ", "resistance", "in base units: 3300 Ω"},
+		{"4700pF", "4.7 nF", "capacitance", "in base units: 4.7e-09 F"},
+		{"100nF", "100 nF", "capacitance", "in base units: 1e-07 F"},
+		{"2.4GHz", "2.4 GHz", "frequency", "in base units: 2400000000 Hz"},
+		{"2.4 GHz", "2.4 GHz", "frequency", "in base units: 2400000000 Hz"},
+		{"0.5mWh", "500 µWh", "energy", "in base units: 0.0005 Wh"},
+		{"10uH", "10 µH", "inductance", "in base units: 1e-05 H"},
+		{"1500kohm", "1.5 MΩ", "resistance", "in base units: 1500000 Ω"},
+		{"230V", "230 V", "voltage", "in base units: 230 V"},
+		{"101.3kPa", "101.3 kPa", "pressure", "in base units: 101300 Pa"},
+	} {
+		gs := guessSIQuantity(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess || gs[0].comment != tc.comment || gs[0].additional[0] != tc.base {
+			t.Errorf("guessSIQuantity(%q) = %+v, want %q (%s), %s", tc.in, gs, tc.guess, tc.comment, tc.base)
+		}
+	}
+	for in, want := range map[string]int{"230V": 100, "100nF": 150, "98.6F": -10, "100C": -10, "5 mC": 150} {
+		if gs := guessSIQuantity(in); len(gs) != 1 || gs[0].goodness != want {
+			t.Errorf("guessSIQuantity(%q) = %+v, want goodness %d", in, gs, want)
+		}
+	}
+	for _, s := range []string{"2M", "kHz", "1.5xF", "3.W", "FF", "1e999V"} {
+		if gs := guessSIQuantity(s); gs != nil {
+			t.Errorf("guessSIQuantity(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessThroughput(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess, comment string
+		additional         []string
+	}{
+		{"1.5GB in 3s", "Throughput 500 MB/s", "1.5GB in 3s", []string{"476.8 MiB/s", "4 Gbit/s"}},
+		{"100MiB/min", "Throughput 1.748 MB/s", "100MiB in 1m0s", []string{"1.667 MiB/s", "13.98 Mbit/s"}},
+		{"10 KB / 2m", "Throughput 83.33 B/s", "10 KB in 2m0s", []string{"83.33 B/s", "666.7 bit/s"}},
+	} {
+		gs := guessThroughput(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess || gs[0].comment != tc.comment || !reflect.DeepEqual(gs[0].additional, tc.additional) {
+			t.Errorf("guessThroughput(%q) = %+v, want %q (%s) %q", tc.in, gs, tc.guess, tc.comment, tc.additional)
+		}
+	}
+	for _, s := range []string{"1.5GB", "1500 in 3s", "1.5GB in 0s", "1.5GB in forever", "10/02/2015", "a/b"} {
+		if gs := guessThroughput(s); gs != nil {
+			t.Errorf("guessThroughput(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessAngle(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess  string
+		additional []string
+	}{
+		{"90deg", "Angle of 90°", []string{"= 1.5708 rad (π/2)", "= 100 grad", "= 0.25 turns"}},
+		{"90°", "Angle of 90°", []string{"= 1.5708 rad (π/2)", "= 100 grad", "= 0.25 turns"}},
+		{"1.5708rad", "Angle of 1.5708 rad", []string{"= 90.0002°", "= 100 grad", "= 0.250001 turns"}},
+		{"100grad", "Angle of 100 grad", []string{"= 90°", "= 1.5708 rad (π/2)", "= 0.25 turns"}},
+		{"-135 deg", "Angle of -135°", []string{"= -2.35619 rad (-3π/4)", "= -150 grad", "= -0.375 turns"}},
+		{"1turn", "Angle of 1 turns", []string{"= 360°", "= 6.28319 rad (2π)", "= 400 grad"}},
+		{"1 rad", "Angle of 1 rad", []string{"= 57.2958°", "= 63.662 grad", "= 0.159155 turns"}},
+	} {
+		gs := guessAngle(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess || !reflect.DeepEqual(gs[0].additional, tc.additional) {
+			t.Errorf("guessAngle(%q) = %+v, want %q %q", tc.in, gs, tc.guess, tc.additional)
+		}
+	}
+	if gs := guessAngle("1.5708rad"); len(gs) != 1 || gs[0].comment != "about π/2" {
+		t.Errorf("guessAngle(1.5708rad) = %+v, want comment about π/2", gs)
+	}
+	for _, s := range []string{"deg", "52°31'N", "90°N", "xdeg", "radar"} {
+		if gs := guessAngle(s); gs != nil {
+			t.Errorf("guessAngle(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessFlagValue(t *testing.T) {
+	if gs := guessFlagValue("1"); gs != nil {
+		t.Errorf("guessFlagValue(1) without -flags = %+v, want nil", gs)
+	}
+	setFlag(t, "flags", "true")
+	for in, want := range map[string]string{
+		"0":  "boolean false",
+		"1":  "boolean true",
+		"-1": "tri-state unset, auto or default",
+	} {
+		gs := guessFlagValue(in)
+		if len(gs) != 1 || gs[0].guess != want {
+			t.Errorf("guessFlagValue(%q) = %+v, want %q", in, gs, want)
+		}
+	}
+	for _, s := range []string{"2", "true", "01", ""} {
+		if gs := guessFlagValue(s); gs != nil {
+			t.Errorf("guessFlagValue(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessCSSUnit(t *testing.T) {
+	for _, tc := range []struct {
+		in, rootPx, guess string
+		first             string
+	}{
+		{"16px", "16", "16 CSS pixels", "1rem"},
+		{"1.5rem", "16", "24 CSS pixels", "24px"},
+		{"1.5rem", "10", "15 CSS pixels", "15px"},
+		{"12pt", "16", "16 CSS pixels", "16px"},
+		{"1in", "16", "96 CSS pixels", "96px"},
+		{"-2em", "16", "-32 CSS pixels", "-32px"},
+	} {
+		setFlag(t, "root-px", tc.rootPx)
+		gs := guessCSSUnit(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess || gs[0].additional[0] != tc.first {
+			t.Errorf("guessCSSUnit(%q) with -root-px %s = %+v, want %q, %q", tc.in, tc.rootPx, gs, tc.guess, tc.first)
+		}
+	}
+	for _, s := range []string{"px", "16", "16 px", "1e3px", "16KiB", "abcpx"} {
+		if gs := guessCSSUnit(s); gs != nil {
+			t.Errorf("guessCSSUnit(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessUnixID(t *testing.T) {
+	if gs := guessUnixID("0"); gs != nil {
+		t.Errorf("guessUnixID(0) without -ids = %+v, want nil", gs)
+	}
+	setFlag(t, "ids", "true")
+	for in, want := range map[string]string{
+		"0":     "root",
+		"33":    "system account, statically allocated",
+		"1000":  "regular user or group",
+		"65534": "nobody/nogroup",
+		"65535": "invalid, -1 as unsigned 16- or 32-bit integer",
+		"62000": "reserved for system use",
+	} {
+		gs := guessUnixID(in)
+		if len(gs) != 1 || gs[0].comment != want {
+			t.Errorf("guessUnixID(%q) = %+v, want %q", in, gs, want)
+		}
+	}
+	for _, s := range []string{"-1", "4294967296", "abc"} {
+		if gs := guessUnixID(s); gs != nil {
+			t.Errorf("guessUnixID(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ranges like 10-20, 1..100 or 0x10-0x20.  Both ends must be numbers, so
+// negative numbers and dates with dashes don't match.
+var rangePattern = regexp.MustCompile(`^(0[xX][0-9a-fA-F]+|\d+)\s*(?:-|–|\.\.)\s*(0[xX][0-9a-fA-F]+|\d+)$`)
+
+// Ranges with at most this many values are listed in full.
+const maxListedRange = 10
+
+// parseRangeEnd parses one end of a range as decimal or 0x-prefixed hex.
+func parseRangeEnd(s string) (uint64, error) {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		return strconv.ParseUint(s[2:], 16, 64)
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// portClasses names the IANA port ranges that lo to hi falls into.
+func portClasses(lo, hi uint64) []string {
+	var classes []string
+	for _, c := range []struct {
+		from, to uint64
+		name     string
+	}{
+		{0, 1023, "well-known"},
+		{1024, 49151, "registered"},
+		{49152, 65535, "dynamic"},
+	} {
+		if lo <= c.to && hi >= c.from {
+			classes = append(classes, c.name)
+		}
+	}
+	return classes
+}
+
+// portLike is true for ranges that look like port ranges, like 8000-8080
+// or 1024-65535, rather than like phone numbers such as 555-1234.
+func portLike(lo, hi uint64, from, to string) bool {
+	if hi > 65535 {
+		return false
+	}
+	for _, b := range []uint64{1023, 1024, 49151, 49152, 65535} {
+		if lo == b || hi == b {
+			return true
+		}
+	}
+	return lo < 100 || len(from) == len(to)
+}
+
+// guessRange recognizes ranges of integers and shows how many values they
+// have and their sum, listing small ones in full.  Where both ends could be
+// ports or recent timestamps, it says so.  Decimal numbers with a hyphen
+// in between are often something else, like phone numbers, so they're
+// unlikely unless they look like ports or timestamps.
+func guessRange(s string) []Guess {
+	m := rangePattern.FindStringSubmatch(s)
+	if m == nil {
+		return nil
+	}
+	lo, err := parseRangeEnd(m[1])
+	if err != nil {
+		return nil
+	}
+	hi, err := parseRangeEnd(m[2])
+	if err != nil || hi <= lo {
+		return nil
+	}
+
+	count := new(big.Int).SetUint64(hi - lo)
+	count.Add(count, big.NewInt(1))
+	sum := new(big.Int).SetUint64(lo)
+	sum.Add(sum, new(big.Int).SetUint64(hi))
+	sum.Mul(sum, count)
+	sum.Rsh(sum, 1)
+
+	g := Guess{
+		guess:   fmt.Sprintf("Range %d to %d", lo, hi),
+		comment: fmt.Sprintf("%s values", count),
+		additional: []string{
+			fmt.Sprintf("sum: %s", sum),
+		},
+		source:   "range",
+		goodness: 100,
+	}
+	if hi-lo < maxListedRange {
+		var vs []string
+		for i := uint64(0); i <= hi-lo; i++ {
+			vs = append(vs, strconv.FormatUint(lo+i, 10))
+		}
+		g.additional = append(g.additional, "values: "+strings.Join(vs, ", "))
+	} else {
+		g.additional = append(g.additional, fmt.Sprintf("values: %d, %d, %d, …, %d", lo, lo+1, lo+2, hi))
+	}
+
+	if hi <= 65535 {
+		g.additional = append(g.additional, "as ports: "+strings.Join(portClasses(lo, hi), " and "))
+	}
+	decimal := !strings.ContainsAny(m[1]+m[2], "xX")
+	if decimal && !strings.Contains(s, "..") && !portLike(lo, hi, m[1], m[2]) {
+		g.goodness = -10
+	}
+	for _, u := range []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Second, "seconds"},
+		{time.Millisecond, "milliseconds"},
+	} {
+		if hi > uint64(1<<63-1)/uint64(u.unit) {
+			continue
+		}
+		from, to := time.Unix(0, int64(lo)*int64(u.unit)), time.Unix(0, int64(hi)*int64(u.unit))
+		if from.Year() < 1990 || to.After(now().AddDate(10, 0, 0)) {
+			continue
+		}
+		g.additional = append(g.additional, fmt.Sprintf("as timestamps (%s): %s to %s, %v", u.name, formatTime(from), formatTime(to), to.Sub(from)))
+		g.goodness = 150
+	}
+	return []Guess{g}
+}
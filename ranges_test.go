@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGuessRange(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess, comment string
+		additional         []string
+		goodness           int
+	}{
+		{"1-5", "Range 1 to 5", "5 values", []string{"sum: 15", "values: 1, 2, 3, 4, 5", "as ports: well-known"}, 100},
+		{"1..100", "Range 1 to 100", "100 values", []string{"sum: 5050", "values: 1, 2, 3, …, 100", "as ports: well-known"}, 100},
+		{"0x10-0x20", "Range 16 to 32", "17 values", []string{"sum: 408", "values: 16, 17, 18, …, 32", "as ports: well-known"}, 100},
+		{"1000 - 50000", "Range 1000 to 50000", "49001 values", []string{"sum: 1249525500", "values: 1000, 1001, 1002, …, 50000", "as ports: well-known and registered and dynamic"}, -10},
+		{"1024-65535", "Range 1024 to 65535", "64512 values", []string{"sum: 2146927104", "values: 1024, 1025, 1026, …, 65535", "as ports: registered and dynamic"}, 100},
+		{"8000-8080", "Range 8000 to 8080", "81 values", []string{"sum: 651240", "values: 8000, 8001, 8002, …, 8080", "as ports: registered"}, 100},
+		{"555-1234", "Range 555 to 1234", "680 values", []string{"sum: 608260", "values: 555, 556, 557, …, 1234", "as ports: well-known and registered"}, -10},
+		{"555..1234", "Range 555 to 1234", "680 values", []string{"sum: 608260", "values: 555, 556, 557, …, 1234", "as ports: well-known and registered"}, 100},
+		{"18446744073709551614-18446744073709551615", "Range 18446744073709551614 to 18446744073709551615", "2 values", []string{"sum: 36893488147419103229", "values: 18446744073709551614, 18446744073709551615"}, -10},
+		{"1443346122-1443349722", "Range 1443346122 to 1443349722", "3601 values", []string{
+			"sum: 5197495867122",
+			"values: 1443346122, 1443346123, 1443346124, …, 1443349722",
+			"as timestamps (seconds): 2015-09-27 09:28:42 +0000 UTC to 2015-09-27 10:28:42 +0000 UTC, 1h0m0s",
+		}, 150},
+	} {
+		gs := guessRange(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess || gs[0].comment != tc.comment || !reflect.DeepEqual(gs[0].additional, tc.additional) || gs[0].goodness != tc.goodness {
+			t.Errorf("guessRange(%q) = %+v, want %q (%s) %q with goodness %d", tc.in, gs, tc.guess, tc.comment, tc.additional, tc.goodness)
+		}
+	}
+	for _, s := range []string{"-5", "20-10", "7-7", "2015-09-27", "1.5-2", "a-b"} {
+		if gs := guessRange(s); gs != nil {
+			t.Errorf("guessRange(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// nearMisses are optional companions to guessers, by guesser name.  When
+// nothing likely was guessed, they explain what's wrong with input that
+// almost has the guesser's format, or return "" if it doesn't.
+var nearMisses = map[string]func(s string) string{
+	"date": nearMissDate,
+	"ip":   nearMissIP,
+	"hex":  nearMissHex,
+}
+
+// suggest collects the near misses for s, in the order of the guessers.
+func suggest(s string) []string {
+	var ss []string
+	for _, gg := range guessers {
+		if f := nearMisses[gg.name]; f != nil {
+			if m := f(s); m != "" {
+				ss = append(ss, "Near miss: "+m)
+			}
+		}
+	}
+	return ss
+}
+
+var nearDateRE = regexp.MustCompile(`^(\d{4})-(\d{1,2})-(\d{1,2})(?:[T ].*)?$`)
+
+// nearMissDate catches ISO 8601 style dates with a month or day out of
+// range, like 2015-02-30.
+func nearMissDate(s string) string {
+	m := nearDateRE.FindStringSubmatch(s)
+	if m == nil {
+		return ""
+	}
+	year, _ := strconv.Atoi(m[1])
+	month, _ := strconv.Atoi(m[2])
+	day, _ := strconv.Atoi(m[3])
+	if month < 1 || month > 12 {
+		return fmt.Sprintf("a date, but there is no month %d", month)
+	}
+	days := time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day < 1 || day > days {
+		return fmt.Sprintf("a date, but %s %d has %d days", time.Month(month), year, days)
+	}
+	return ""
+}
+
+// nearMissIP catches dotted quads with an octet greater than 255.
+func nearMissIP(s string) string {
+	parts := strings.Split(s, ".")
+	if len(parts) != 4 {
+		return ""
+	}
+	bad := ""
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return ""
+		}
+		if n > 255 && bad == "" {
+			bad = p
+		}
+	}
+	if bad == "" {
+		return ""
+	}
+	return fmt.Sprintf("an IPv4 address, but %s is greater than 255", bad)
+}
+
+// nearMissHex catches hex numbers and IDs with a single stray character,
+// as in deadbeeg.
+func nearMissHex(s string) string {
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if len(digits) < 8 {
+		return ""
+	}
+	stray := -1
+	for i, c := range digits {
+		if !isHex(string(c)) {
+			if stray >= 0 {
+				return ""
+			}
+			stray = i
+		}
+	}
+	if stray < 0 {
+		return ""
+	}
+	r := []rune(digits[stray:])[0]
+	return fmt.Sprintf("hexadecimal, except for %q at position %d", r, len(s)-len(digits)+stray+1)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want []string
+	}{
+		{"2015-02-30", []string{"Near miss: a date, but February 2015 has 28 days"}},
+		{"2016-02-30T12:00:00Z", []string{"Near miss: a date, but February 2016 has 29 days"}},
+		{"2015-13-01", []string{"Near miss: a date, but there is no month 13"}},
+		{"10.0.300.1", []string{"Near miss: an IPv4 address, but 300 is greater than 255"}},
+		{"0xdeadbeeg", []string{`Near miss: hexadecimal, except for 'g' at position 10`}},
+		{"5607b6ca1a2b3c4d5e00010z", []string{`Near miss: hexadecimal, except for 'z' at position 24`}},
+		{"2015-09-27", nil},
+		{"10.0.0.1", nil},
+		{"deadbeef", nil},
+		{"hello world", nil},
+		{"1.2.3", nil},
+	} {
+		if got := suggest(tc.in); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("suggest(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The observer's position given with -at, in degrees.  Latitude is positive
+// to the north, longitude to the east.
+var (
+	observerSet bool
+	observerLat float64
+	observerLon float64
+)
+
+// The altitude of the sun's center at sunrise, which accounts for refraction
+// and the size of the sun's disk.
+const sunriseAltitude = -0.833
+
+func parseCoordinates(s string) (lat, lon float64, err error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("cannot parse %q as LAT,LON", s)
+	}
+	lat, err = strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err == nil {
+		lon, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot parse %q as LAT,LON: %v", s, err)
+	}
+	if math.Abs(lat) > 90 || math.Abs(lon) > 180 {
+		return 0, 0, fmt.Errorf("coordinates %q out of range", s)
+	}
+	return lat, lon, nil
+}
+
+func formatCoordinates(lat, lon float64) string {
+	ns, ew := "N", "E"
+	if lat < 0 {
+		ns = "S"
+	}
+	if lon < 0 {
+		ew = "W"
+	}
+	deg := "°"
+	if *asciiOnly {
+		deg = " deg "
+	}
+	return fmt.Sprintf("%.4f%s%s %.4f%s%s", math.Abs(lat), deg, ns, math.Abs(lon), deg, ew)
+}
+
+func sinDeg(deg float64) float64 { return math.Sin(deg * math.Pi / 180) }
+func cosDeg(deg float64) float64 { return math.Cos(deg * math.Pi / 180) }
+
+// sunTimes computes sunrise and sunset for the calendar day of t at the
+// given position, using the sunrise equation as described at
+// https://en.wikipedia.org/wiki/Sunrise_equation.  It is accurate to about
+// a minute.  If the sun doesn't rise or set on that day, polar is "polar
+// day" or "polar night".
+func sunTimes(t time.Time, lat, lon float64) (rise, set time.Time, polar string) {
+	const j2000 = 2451545.0
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	// Days since noon on 2000-01-01, which is 10957.5 days after the
+	// UNIX epoch, plus a correction for leap seconds and terrestrial
+	// time.
+	n := math.Round(float64(day.Unix())/86400-10957.5) + 0.0008
+	jstar := n - lon/360
+	m := math.Mod(357.5291+0.98560028*jstar, 360)
+	c := 1.9148*sinDeg(m) + 0.0200*sinDeg(2*m) + 0.0003*sinDeg(3*m)
+	lambda := math.Mod(m+c+180+102.9372, 360)
+	transit := j2000 + jstar + 0.0053*sinDeg(m) - 0.0069*sinDeg(2*lambda)
+	sinDecl := sinDeg(lambda) * sinDeg(23.4397)
+	cosDecl := math.Cos(math.Asin(sinDecl))
+	cosHourAngle := (sinDeg(sunriseAltitude) - sinDeg(lat)*sinDecl) / (cosDeg(lat) * cosDecl)
+	switch {
+	case cosHourAngle < -1:
+		return time.Time{}, time.Time{}, "polar day"
+	case cosHourAngle > 1:
+		return time.Time{}, time.Time{}, "polar night"
+	}
+	hourAngle := math.Acos(cosHourAngle) * 180 / math.Pi
+	fromJD := func(jd float64) time.Time {
+		secs := (jd - jdUnixEpoch) * 86400
+		return time.Unix(0, 0).Add(time.Duration(math.Round(secs)) * time.Second)
+	}
+	return fromJD(transit - hourAngle/360), fromJD(transit + hourAngle/360), ""
+}
+
+// sunLines describes sunrise and sunset on the day of t at the position
+// given with -at, if any.
+func sunLines(t time.Time) []string {
+	if !observerSet {
+		return nil
+	}
+	at := formatCoordinates(observerLat, observerLon)
+	rise, set, polar := sunTimes(t, observerLat, observerLon)
+	if polar != "" {
+		return []string{fmt.Sprintf("At %s: %s", at, polar)}
+	}
+	return []string{
+		fmt.Sprintf("At %s:", at),
+		fmt.Sprintf("sunrise %s", formatTime(rise.In(t.Location()))),
+		fmt.Sprintf("sunset %s", formatTime(set.In(t.Location()))),
+	}
+}
+
+// A new moon to count lunations from, and the mean length of one.
+var (
+	knownNewMoon = time.Date(2000, 1, 6, 18, 14, 0, 0, time.UTC)
+	synodicMonth = 29.530588853 * 24 * float64(time.Hour)
+)
+
+// moonPhaseName names the phase p, as returned by moonPhase.  The four
+// principal phases are instants, so they are given a day and a half either
+// side, which also covers the error of using the mean synodic month.
+func moonPhaseName(p float64) string {
+	window := float64(36*time.Hour) / synodicMonth
+	for _, ph := range []struct {
+		at   float64
+		name string
+	}{{0, "new moon"}, {0.25, "first quarter"}, {0.5, "full moon"}, {0.75, "last quarter"}, {1, "new moon"}} {
+		if math.Abs(p-ph.at) <= window {
+			return ph.name
+		}
+	}
+	switch {
+	case p < 0.25:
+		return "waxing crescent"
+	case p < 0.5:
+		return "waxing gibbous"
+	case p < 0.75:
+		return "waning gibbous"
+	}
+	return "waning crescent"
+}
+
+// moonPhase returns the phase of the moon at t as a fraction of the
+// lunation, 0 at new moon and 0.5 at full moon, using the mean synodic
+// month.  That is off by up to about half a day from the true phase.
+func moonPhase(t time.Time) float64 {
+	p := math.Mod(float64(t.Sub(knownNewMoon)), synodicMonth) / synodicMonth
+	if p < 0 {
+		p++
+	}
+	return p
+}
+
+// moonLines describes the phase of the moon at t for -moon.
+func moonLines(t time.Time) []string {
+	if !*showMoon {
+		return nil
+	}
+	p := moonPhase(t)
+	lit := (1 - math.Cos(2*math.Pi*p)) / 2
+	return []string{fmt.Sprintf("Moon: %s, %.0f%% illuminated", moonPhaseName(p), 100*lit)}
+}
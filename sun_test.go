@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSunTimes(t *testing.T) {
+	near := func(got time.Time, want string) bool {
+		w, err := time.Parse(time.RFC3339, want)
+		if err != nil {
+			panic(err)
+		}
+		d := got.Sub(w)
+		return d > -2*time.Minute && d < 2*time.Minute
+	}
+	for _, tc := range []struct {
+		day       time.Time
+		lat, lon  float64
+		rise, set string
+	}{
+		// Berlin on the summer solstice
+		{time.Date(2015, 6, 21, 12, 0, 0, 0, time.UTC), 52.52, 13.405, "2015-06-21T02:43:00Z", "2015-06-21T19:33:00Z"},
+		// Sydney in the southern spring
+		{time.Date(2015, 9, 27, 12, 0, 0, 0, time.UTC), -33.87, 151.21, "2015-09-26T19:40:00Z", "2015-09-27T07:55:00Z"},
+	} {
+		rise, set, polar := sunTimes(tc.day, tc.lat, tc.lon)
+		if polar != "" || !near(rise, tc.rise) || !near(set, tc.set) {
+			t.Errorf("sunTimes(%v, %v, %v) = %v, %v, %q, want about %s, %s", tc.day, tc.lat, tc.lon, rise.UTC(), set.UTC(), polar, tc.rise, tc.set)
+		}
+	}
+	if _, _, polar := sunTimes(time.Date(2015, 6, 21, 0, 0, 0, 0, time.UTC), 78.2, 15.6); polar != "polar day" {
+		t.Errorf("Svalbard in June: %q, want polar day", polar)
+	}
+	if _, _, polar := sunTimes(time.Date(2015, 12, 21, 0, 0, 0, 0, time.UTC), 78.2, 15.6); polar != "polar night" {
+		t.Errorf("Svalbard in December: %q, want polar night", polar)
+	}
+}
+
+func TestParseCoordinates(t *testing.T) {
+	lat, lon, err := parseCoordinates("52.52, -13.405")
+	if err != nil || lat != 52.52 || lon != -13.405 {
+		t.Errorf("parseCoordinates() = %v, %v, %v", lat, lon, err)
+	}
+	for _, s := range []string{"52.52", "91,0", "0,181", "a,b"} {
+		if _, _, err := parseCoordinates(s); err == nil {
+			t.Errorf("parseCoordinates(%q) succeeded", s)
+		}
+	}
+	if got, want := formatCoordinates(-33.87, 151.21), "33.8700°S 151.2100°E"; got != want {
+		t.Errorf("formatCoordinates() = %q, want %q", got, want)
+	}
+}
+
+func TestMoonLines(t *testing.T) {
+	if got := moonLines(testNow); got != nil {
+		t.Errorf("moonLines() without -moon = %q", got)
+	}
+	setFlag(t, "moon", "true")
+	for _, tc := range []struct {
+		t    time.Time
+		want string
+	}{
+		// The lunar eclipse of 2015-09-28 02:47 UTC.
+		{testNow, "Moon: full moon, 99% illuminated"},
+		{time.Date(2015, 9, 13, 6, 41, 0, 0, time.UTC), "Moon: new moon, 0% illuminated"},
+		{time.Date(2015, 9, 21, 8, 59, 0, 0, time.UTC), "Moon: first quarter, 53% illuminated"},
+		{time.Date(2015, 10, 4, 21, 6, 0, 0, time.UTC), "Moon: last quarter, 60% illuminated"},
+		{time.Date(2015, 9, 17, 0, 0, 0, 0, time.UTC), "Moon: waxing crescent, 12% illuminated"},
+		{time.Date(2015, 9, 24, 0, 0, 0, 0, time.UTC), "Moon: waxing gibbous, 79% illuminated"},
+		{time.Date(2015, 10, 1, 0, 0, 0, 0, time.UTC), "Moon: waning gibbous, 93% illuminated"},
+	} {
+		if got := moonLines(tc.t); len(got) != 1 || got[0] != tc.want {
+			t.Errorf("moonLines(%v) = %q, want %q", tc.t, got, tc.want)
+		}
+	}
+}
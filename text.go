@@ -0,0 +1,309 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Spellings of the escape character as they appear in shell scripts,
+// printf format strings and source code.
+var escSpellings = []string{`\x1b`, `\x1B`, `\033`, `\e`, `\u001b`, `\u001B`, `^[`}
+
+var sgrRE = regexp.MustCompile("\x1b\\[([0-9;:]*)m")
+
+var sgrColors = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+var sgrAttributes = map[int]string{
+	0:  "reset",
+	1:  "bold",
+	2:  "faint",
+	3:  "italic",
+	4:  "underline",
+	5:  "slow blink",
+	6:  "rapid blink",
+	7:  "reverse video",
+	8:  "hidden",
+	9:  "crossed out",
+	21: "double underline",
+	22: "normal intensity",
+	23: "not italic",
+	24: "not underlined",
+	25: "not blinking",
+	27: "not reversed",
+	28: "not hidden",
+	29: "not crossed out",
+	39: "default foreground",
+	49: "default background",
+	53: "overlined",
+	55: "not overlined",
+}
+
+func guessANSI(s string) []Guess {
+	for _, e := range escSpellings {
+		s = strings.ReplaceAll(s, e, "\x1b")
+	}
+	matches := sgrRE.FindAllStringSubmatch(s, -1)
+	if matches == nil {
+		return nil
+	}
+	var additional []string
+	for _, m := range matches {
+		additional = append(additional, fmt.Sprintf("ESC[%sm: %s", m[1], describeSGR(m[1])))
+	}
+	return []Guess{{
+		guess:      "ANSI escape sequence",
+		comment:    "Select Graphic Rendition",
+		additional: additional,
+		source:     "ANSI SGR sequence",
+		goodness:   120,
+	}}
+}
+
+// describeSGR explains the semicolon separated parameters of an SGR
+// sequence, e.g. "1;31" is "bold, red foreground".
+func describeSGR(params string) string {
+	if params == "" {
+		return "reset"
+	}
+	var ps []int
+	for _, p := range strings.FieldsFunc(params, func(r rune) bool { return r == ';' || r == ':' }) {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return "(invalid parameter " + p + ")"
+		}
+		ps = append(ps, n)
+	}
+	var desc []string
+	for i := 0; i < len(ps); i++ {
+		p := ps[i]
+		switch {
+		case p >= 30 && p <= 37:
+			desc = append(desc, sgrColors[p-30]+" foreground")
+		case p >= 40 && p <= 47:
+			desc = append(desc, sgrColors[p-40]+" background")
+		case p >= 90 && p <= 97:
+			desc = append(desc, "bright "+sgrColors[p-90]+" foreground")
+		case p >= 100 && p <= 107:
+			desc = append(desc, "bright "+sgrColors[p-100]+" background")
+		case p == 38 || p == 48 || p == 58:
+			what := map[int]string{38: "foreground", 48: "background", 58: "underline color"}[p]
+			if i+2 < len(ps) && ps[i+1] == 5 {
+				desc = append(desc, fmt.Sprintf("256-color %s %d", what, ps[i+2]))
+				i += 2
+			} else if i+4 < len(ps) && ps[i+1] == 2 {
+				desc = append(desc, fmt.Sprintf("RGB %s #%02x%02x%02x", what, ps[i+2], ps[i+3], ps[i+4]))
+				i += 4
+			} else {
+				desc = append(desc, "(incomplete extended "+what+")")
+			}
+		default:
+			a, ok := sgrAttributes[p]
+			if !ok {
+				a = fmt.Sprintf("(unknown attribute %d)", p)
+			}
+			desc = append(desc, a)
+		}
+	}
+	return strings.Join(desc, ", ")
+}
+
+var morseCode = map[rune]string{
+	'A': ".-", 'B': "-...", 'C': "-.-.", 'D': "-..", 'E': ".", 'F': "..-.",
+	'G': "--.", 'H': "....", 'I': "..", 'J': ".---", 'K': "-.-", 'L': ".-..",
+	'M': "--", 'N': "-.", 'O': "---", 'P': ".--.", 'Q': "--.-", 'R': ".-.",
+	'S': "...", 'T': "-", 'U': "..-", 'V': "...-", 'W': ".--", 'X': "-..-",
+	'Y': "-.--", 'Z': "--..",
+	'0': "-----", '1': ".----", '2': "..---", '3': "...--", '4': "....-",
+	'5': ".....", '6': "-....", '7': "--...", '8': "---..", '9': "----.",
+	'.': ".-.-.-", ',': "--..--", '?': "..--..", '/': "-..-.", '@': ".--.-.",
+	'=': "-...-", '+': ".-.-.", '!': "-.-.--", '(': "-.--.", ')': "-.--.-",
+	'&': ".-...", ':': "---...", ';': "-.-.-.", '-': "-....-", '\'': ".----.",
+	'"': ".-..-.",
+}
+
+var morseDecode = func() map[string]rune {
+	m := make(map[string]rune)
+	for r, code := range morseCode {
+		m[code] = r
+	}
+	return m
+}()
+
+func guessMorse(s string) []Guess {
+	if strings.Trim(s, ".-_ /|") != "" {
+		return nil
+	}
+	if strings.Count(s, ".")+strings.Count(s, "-")+strings.Count(s, "_") < 2 {
+		return nil
+	}
+	s = strings.ReplaceAll(s, "_", "-")
+	var words []string
+	unknown := 0
+	for _, w := range strings.FieldsFunc(s, func(r rune) bool { return r == '/' || r == '|' }) {
+		var word []rune
+		for _, code := range strings.Fields(w) {
+			r, ok := morseDecode[code]
+			if !ok {
+				r = '?'
+				unknown++
+			}
+			word = append(word, r)
+		}
+		if len(word) > 0 {
+			words = append(words, string(word))
+		}
+	}
+	if len(words) == 0 {
+		return nil
+	}
+	good := 60
+	if unknown > 0 {
+		good = 10
+	}
+	return []Guess{{
+		guess:    "Morse code for " + strings.Join(words, " "),
+		source:   "Morse code",
+		goodness: good,
+	}}
+}
+
+// encodeMorse returns the Morse code for alphanumeric text, or "" if s
+// contains anything else.
+func encodeMorse(s string) string {
+	var words []string
+	for _, w := range strings.Fields(strings.ToUpper(s)) {
+		var codes []string
+		for _, r := range w {
+			if !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+				return ""
+			}
+			codes = append(codes, morseCode[r])
+		}
+		words = append(words, strings.Join(codes, " "))
+	}
+	return strings.Join(words, " / ")
+}
+
+// Braille cells of the English alphabet and common punctuation (grade 1).
+// Bit n-1 of the offset from U+2800 corresponds to dot n.
+var brailleCells = map[rune]rune{
+	'a': 0x2801, 'b': 0x2803, 'c': 0x2809, 'd': 0x2819, 'e': 0x2811,
+	'f': 0x280b, 'g': 0x281b, 'h': 0x2813, 'i': 0x280a, 'j': 0x281a,
+	'k': 0x2805, 'l': 0x2807, 'm': 0x280d, 'n': 0x281d, 'o': 0x2815,
+	'p': 0x280f, 'q': 0x281f, 'r': 0x2817, 's': 0x280e, 't': 0x281e,
+	'u': 0x2825, 'v': 0x2827, 'w': 0x283a, 'x': 0x282d, 'y': 0x283d,
+	'z': 0x2835,
+	',': 0x2802, ';': 0x2806, ':': 0x2812, '.': 0x2832, '!': 0x2816,
+	'?': 0x2826, '\'': 0x2804, '-': 0x2824, ' ': 0x2800,
+}
+
+const (
+	brailleCapital = 0x2820
+	brailleNumber  = 0x283c
+)
+
+var brailleLetters = func() map[rune]rune {
+	m := make(map[rune]rune)
+	for r, cell := range brailleCells {
+		m[cell] = r
+	}
+	return m
+}()
+
+func guessBraille(s string) []Guess {
+	var out []rune
+	capital, number, unknown := false, false, 0
+	for _, r := range s {
+		if r < 0x2800 || r > 0x28ff {
+			return nil
+		}
+		switch r {
+		case brailleCapital:
+			capital = true
+			continue
+		case brailleNumber:
+			number = true
+			continue
+		case 0x2800:
+			number = false
+		}
+		l, ok := brailleLetters[r]
+		switch {
+		case !ok:
+			l = '?'
+			unknown++
+		case number && l >= 'a' && l <= 'j':
+			// The number sign turns a-j into 1-9 and 0.
+			l = '0' + (l-'a'+1)%10
+		case capital:
+			l = l - 'a' + 'A'
+		}
+		capital = false
+		out = append(out, l)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	good := 80
+	if unknown > 0 {
+		good = 20
+	}
+	return []Guess{{
+		guess:    "Braille for " + string(out),
+		source:   "Braille",
+		goodness: good,
+	}}
+}
+
+// encodeBraille returns s in grade 1 Braille, or "" if s contains
+// characters without a Braille cell.
+func encodeBraille(s string) string {
+	var out []rune
+	number := false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			if !number {
+				out = append(out, brailleNumber)
+				number = true
+			}
+			out = append(out, brailleCells['a'+(r-'0'+9)%10])
+			continue
+		case r >= 'A' && r <= 'Z':
+			out = append(out, brailleCapital)
+			r = r - 'A' + 'a'
+		}
+		cell, ok := brailleCells[r]
+		if !ok {
+			return ""
+		}
+		number = false
+		out = append(out, cell)
+	}
+	return string(out)
+}
+
+// guessText shows short plain text in other writing systems.
+func guessText(s string) []Guess {
+	if len(s) > 20 {
+		return nil
+	}
+	var additional []string
+	if m := encodeMorse(s); m != "" {
+		additional = append(additional, "in Morse code: "+m)
+	}
+	if b := encodeBraille(s); b != "" {
+		additional = append(additional, "in Braille: "+b)
+	}
+	if additional == nil {
+		return nil
+	}
+	return []Guess{{
+		guess:      "Text " + s,
+		additional: additional,
+		source:     "plain text",
+		goodness:   -10,
+	}}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGuessANSI(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want []string
+	}{
+		{`\x1b[1;31m`, []string{"ESC[1;31m: bold, red foreground"}},
+		{`\033[0m`, []string{"ESC[0m: reset"}},
+		{"\x1b[m", []string{"ESC[m: reset"}},
+		{`\e[38;5;208mfoo\e[49m`, []string{"ESC[38;5;208m: 256-color foreground 208", "ESC[49m: default background"}},
+		{`^[[48;2;255;0;10;4m`, []string{"ESC[48;2;255;0;10;4m: RGB background #ff000a, underline"}},
+		{`\x1b[97;100m`, []string{"ESC[97;100m: bright white foreground, bright black background"}},
+	} {
+		gs := guessANSI(tc.in)
+		if len(gs) != 1 || !reflect.DeepEqual(gs[0].additional, tc.want) {
+			t.Errorf("guessANSI(%q) = %+v, want %q", tc.in, gs, tc.want)
+		}
+	}
+	if gs := guessANSI("[1;31m"); gs != nil {
+		t.Errorf("guessANSI() without escape = %+v, want nil", gs)
+	}
+}
+
+func TestGuessMorse(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+		good     int
+	}{
+		{".... . .-.. .-.. --- / .-- --- .-. .-.. -..", "Morse code for HELLO WORLD", 60},
+		{"... --- ...", "Morse code for SOS", 60},
+		{"___ ...", "Morse code for OS", 60},
+		{"........", "Morse code for ?", 10},
+	} {
+		gs := guessMorse(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.want || gs[0].goodness != tc.good {
+			t.Errorf("guessMorse(%q) = %+v, want %q", tc.in, gs, tc.want)
+		}
+	}
+	for _, s := range []string{"-", ".", "-5", "a.b", "/"} {
+		if gs := guessMorse(s); gs != nil {
+			t.Errorf("guessMorse(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestGuessBraille(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"⠠⠓⠑⠇⠇⠕⠀⠼⠙⠃", "Braille for Hello 42"},
+		{"⠼⠁⠚⠁", "Braille for 101"},
+		{"⠿", "Braille for ?"},
+	} {
+		gs := guessBraille(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.want {
+			t.Errorf("guessBraille(%q) = %+v, want %q", tc.in, gs, tc.want)
+		}
+	}
+	if gs := guessBraille("⠓i"); gs != nil {
+		t.Errorf("guessBraille() with non-Braille = %+v, want nil", gs)
+	}
+}
+
+func TestGuessText(t *testing.T) {
+	gs := guessText("Hi 42")
+	want := []string{"in Morse code: .... .. / ....- ..---", "in Braille: ⠠⠓⠊⠀⠼⠙⠃"}
+	if len(gs) != 1 || !reflect.DeepEqual(gs[0].additional, want) {
+		t.Errorf("guessText() = %+v, want %q", gs, want)
+	}
+	if gs := guessText("#~#"); gs != nil {
+		t.Errorf("guessText(%q) = %+v, want nil", "#~#", gs)
+	}
+}
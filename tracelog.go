@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// newTraceLogger returns the logger for -trace in the given -trace-format:
+// human-readable lines, logfmt key=value pairs or JSON objects, one per
+// message.
+func newTraceLogger(w io.Writer, format string) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug}
+	switch format {
+	case "text":
+		return slog.New(&lineHandler{l: log.New(w, "TRACE: ", log.LstdFlags)}), nil
+	case "logfmt":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("invalid -trace-format %q, must be text, logfmt or json", format)
+}
+
+// A lineHandler writes log records the way -trace always has, as the
+// message after a prefix and timestamp, followed by any attributes as
+// key=value pairs, with values quoted where they would be ambiguous.
+type lineHandler struct {
+	l     *log.Logger
+	attrs []slog.Attr
+}
+
+func (h *lineHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *lineHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString(r.Message)
+	add := func(a slog.Attr) bool {
+		v := a.Value.String()
+		if needsQuoting(v) {
+			v = strconv.Quote(v)
+		}
+		fmt.Fprintf(&b, " %s=%s", a.Key, v)
+		return true
+	}
+	for _, a := range h.attrs {
+		add(a)
+	}
+	r.Attrs(add)
+	h.l.Print(b.String())
+	return nil
+}
+
+func (h *lineHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &lineHandler{l: h.l, attrs: append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...)}
+}
+
+// needsQuoting is true for values that are empty or have spaces, quotes,
+// equals signs or unprintable characters in them, like input="foo bar".
+func needsQuoting(s string) bool {
+	return s == "" || strings.IndexFunc(s, func(r rune) bool {
+		return r == ' ' || r == '"' || r == '=' || !unicode.IsPrint(r)
+	}) >= 0
+}
+
+// WithGroup ignores groups, which -trace doesn't use.
+func (h *lineHandler) WithGroup(string) slog.Handler { return h }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestNewTraceLogger(t *testing.T) {
+	for _, tc := range []struct {
+		format string
+		want   *regexp.Regexp
+	}{
+		{"text", regexp.MustCompile(`^TRACE: \d{4}/\d\d/\d\d \d\d:\d\d:\d\d guesser done guesser=hex likely=1\n$`)},
+		{"logfmt", regexp.MustCompile(`^time=\S+ level=DEBUG msg="guesser done" guesser=hex likely=1\n$`)},
+	} {
+		var buf bytes.Buffer
+		l, err := newTraceLogger(&buf, tc.format)
+		if err != nil {
+			t.Fatalf("newTraceLogger(%s) = %v", tc.format, err)
+		}
+		l.Debug("guesser done", "guesser", "hex", "likely", 1)
+		if !tc.want.MatchString(buf.String()) {
+			t.Errorf("%s trace output is %q, want match for %s", tc.format, buf.String(), tc.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	l, err := newTraceLogger(&buf, "json")
+	if err != nil {
+		t.Fatalf("newTraceLogger(json) = %v", err)
+	}
+	l.With("input", "0x10").Debug("guesser done", "guesser", "hex")
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("json trace output %q: %v", buf.String(), err)
+	}
+	if m["msg"] != "guesser done" || m["guesser"] != "hex" || m["input"] != "0x10" || m["level"] != "DEBUG" {
+		t.Errorf("json trace output is %v", m)
+	}
+
+	if _, err := newTraceLogger(&buf, "xml"); err == nil || !strings.Contains(err.Error(), "text, logfmt or json") {
+		t.Errorf("newTraceLogger(xml) = %v, want an error", err)
+	}
+}
+
+func TestLineHandlerWithAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := newTraceLogger(&buf, "text")
+	l.With("input", "0x10").Debug("guesser done", "guesser", "hex")
+	if got := buf.String(); !strings.HasSuffix(got, " guesser done input=0x10 guesser=hex\n") {
+		t.Errorf("text trace output is %q", got)
+	}
+	buf.Reset()
+	l.Debug("trying to guess", "input", "foo bar", "other", "a=b", "empty", "", "tab", "\t", "likely", 2)
+	if got := buf.String(); !strings.HasSuffix(got, ` trying to guess input="foo bar" other="a=b" empty="" tab="\t" likely=2`+"\n") {
+		t.Errorf("text trace output is %q", got)
+	}
+}
+
+func TestTraceAttrs(t *testing.T) {
+	defer func(l *slog.Logger) { Trace = l }(Trace)
+	setFlag(t, "trace", "true")
+	for _, tc := range []struct {
+		format, want string
+	}{
+		{"text", ` Trying to guess "foo bar"` + "\n"},
+		{"logfmt", ` msg="trying to guess" input="foo bar"` + "\n"},
+	} {
+		setFlag(t, "trace-format", tc.format)
+		var buf bytes.Buffer
+		Trace, _ = newTraceLogger(&buf, tc.format)
+		traceAttrs(`Trying to guess "foo bar"`, "trying to guess", "input", "foo bar")
+		if got := buf.String(); !strings.HasSuffix(got, tc.want) {
+			t.Errorf("%s trace output is %q, want suffix %q", tc.format, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var percentEscapeRE = regexp.MustCompile(`%[0-9A-Fa-f]{2}`)
+
+// guessPercentEncoded decodes the %XX escapes of URLs.  Consecutive escapes
+// are decoded into bytes first, so that multi-byte UTF-8 like %E2%9C%93
+// comes out as one character instead of mojibake; if the bytes aren't
+// valid UTF-8 they are shown in hex.
+func guessPercentEncoded(s string) []Guess {
+	if !percentEscapeRE.MatchString(s) {
+		return nil
+	}
+	text, err := url.PathUnescape(s)
+	if err != nil {
+		trace("cannot decode %q as percent-encoded: %v", s, err)
+		return nil
+	}
+	g := Guess{
+		guess:    "Percent-encoded text " + text,
+		source:   "percent-encoding",
+		goodness: 100,
+	}
+	if !utf8.ValidString(text) {
+		g.guess = fmt.Sprintf("Percent-encoded bytes % x", text)
+		g.comment = "not valid UTF-8"
+		g.goodness = 20
+		return []Guess{g}
+	}
+	if strings.Contains(s, "+") {
+		if q, err := url.QueryUnescape(s); err == nil {
+			g.additional = []string{"in a query string, with + as space: " + q}
+		}
+	}
+	return []Guess{g}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGuessPercentEncoded(t *testing.T) {
+	for _, tc := range []struct {
+		in, guess, comment string
+		additional         []string
+	}{
+		{"%E2%9C%93", "Percent-encoded text ✓", "", nil},
+		{"%F0%9F%98%80%20smile", "Percent-encoded text 😀 smile", "", nil},
+		{"caf%C3%A9", "Percent-encoded text café", "", nil},
+		{"a+b%3Dc", "Percent-encoded text a+b=c", "", []string{"in a query string, with + as space: a b=c"}},
+		{"caf%E9", "Percent-encoded bytes 63 61 66 e9", "not valid UTF-8", nil},
+		{"%F0%9F%98", "Percent-encoded bytes f0 9f 98", "not valid UTF-8", nil},
+	} {
+		gs := guessPercentEncoded(tc.in)
+		if len(gs) != 1 || gs[0].guess != tc.guess || gs[0].comment != tc.comment || !reflect.DeepEqual(gs[0].additional, tc.additional) {
+			t.Errorf("guessPercentEncoded(%q) = %+v, want %q (%s) %q", tc.in, gs, tc.guess, tc.comment, tc.additional)
+		}
+	}
+	for _, s := range []string{"100%", "50% off", "%zz", "%E2%9C%9 3%"} {
+		if gs := guessPercentEncoded(s); gs != nil {
+			t.Errorf("guessPercentEncoded(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// version is set at build time with -ldflags "-X main.version=v1.2.3";
+// otherwise the module version from the build info is used, if any.
+var version = ""
+
+// versionString describes the version and how the program was built, for
+// -version.
+func versionString(bi *debug.BuildInfo, ok bool) string {
+	v := version
+	if v == "" && ok && bi.Main.Version != "" {
+		v = bi.Main.Version
+	}
+	if v == "" {
+		v = "(devel)"
+	}
+	var details []string
+	if ok {
+		settings := map[string]string{}
+		for _, s := range bi.Settings {
+			settings[s.Key] = s.Value
+		}
+		if rev := settings["vcs.revision"]; rev != "" {
+			if len(rev) > 12 {
+				rev = rev[:12]
+			}
+			if settings["vcs.modified"] == "true" {
+				rev += "+modified"
+			}
+			details = append(details, "revision "+rev)
+		}
+		if t := settings["vcs.time"]; t != "" {
+			details = append(details, "committed "+t)
+		}
+		details = append(details, "built with "+bi.GoVersion)
+	}
+	if details == nil {
+		return "guess " + v + "\n"
+	}
+	return fmt.Sprintf("guess %s (%s)\n", v, strings.Join(details, ", "))
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestVersionString(t *testing.T) {
+	bi := &debug.BuildInfo{
+		GoVersion: "go1.21.0",
+		Main:      debug.Module{Path: "guess", Version: "(devel)"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "45cb1d8e0a4b1f2c3d4e5f60718293a4b5c6d7e8"},
+			{Key: "vcs.time", Value: "2015-09-27T09:28:42Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+	want := "guess (devel) (revision 45cb1d8e0a4b+modified, committed 2015-09-27T09:28:42Z, built with go1.21.0)\n"
+	if got := versionString(bi, true); got != want {
+		t.Errorf("versionString() = %q, want %q", got, want)
+	}
+
+	old := version
+	version = "v1.2.3"
+	defer func() { version = old }()
+	if got, want := versionString(&debug.BuildInfo{GoVersion: "go1.21.0"}, true), "guess v1.2.3 (built with go1.21.0)\n"; got != want {
+		t.Errorf("versionString() with -X main.version = %q, want %q", got, want)
+	}
+	if got, want := versionString(nil, false), "guess v1.2.3\n"; got != want {
+		t.Errorf("versionString() without build info = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Values of VIN characters for the check digit, see ISO 3779 and 49 CFR
+// 565.  I, O and Q are not used.
+var vinValues = map[rune]int{
+	'A': 1, 'B': 2, 'C': 3, 'D': 4, 'E': 5, 'F': 6, 'G': 7, 'H': 8,
+	'J': 1, 'K': 2, 'L': 3, 'M': 4, 'N': 5, 'P': 7, 'R': 9,
+	'S': 2, 'T': 3, 'U': 4, 'V': 5, 'W': 6, 'X': 7, 'Y': 8, 'Z': 9,
+}
+
+var vinWeights = []int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// Model year codes in position 10, repeating every 30 years from 1980.
+const vinYearCodes = "ABCDEFGHJKLMNPRSTVWXY123456789"
+
+// Regions by the first character of the world manufacturer identifier.
+var vinRegions = []struct {
+	from, to byte
+	region   string
+}{
+	{'1', '1', "United States"},
+	{'2', '2', "Canada"},
+	{'3', '3', "Mexico"},
+	{'4', '5', "United States"},
+	{'6', '6', "Australia"},
+	{'7', '7', "New Zealand"},
+	{'8', '8', "South America"},
+	{'9', '9', "Brazil"},
+	{'A', 'H', "Africa"},
+	{'J', 'J', "Japan"},
+	{'K', 'K', "South Korea"},
+	{'L', 'L', "China"},
+	{'M', 'M', "India or Southeast Asia"},
+	{'N', 'N', "Turkey or Central Asia"},
+	{'P', 'R', "Asia"},
+	{'S', 'S', "United Kingdom or Central Europe"},
+	{'T', 'T', "Central Europe"},
+	{'U', 'U', "Eastern Europe"},
+	{'V', 'V', "France or Spain"},
+	{'W', 'W', "Germany"},
+	{'X', 'X', "Russia or Eastern Europe"},
+	{'Y', 'Y', "Northern Europe"},
+	{'Z', 'Z', "Italy"},
+}
+
+// Some well-known world manufacturer identifiers.
+var vinManufacturers = map[string]string{
+	"1C3": "Chrysler",
+	"1FA": "Ford",
+	"1FT": "Ford (truck)",
+	"1G1": "Chevrolet",
+	"1GC": "Chevrolet (truck)",
+	"1HG": "Honda (USA)",
+	"1J4": "Jeep",
+	"1M8": "Motor Coach Industries",
+	"1N4": "Nissan (USA)",
+	"2HG": "Honda (Canada)",
+	"2T1": "Toyota (Canada)",
+	"3FA": "Ford (Mexico)",
+	"3VW": "Volkswagen (Mexico)",
+	"4T1": "Toyota (USA)",
+	"5YJ": "Tesla",
+	"JHM": "Honda",
+	"JN1": "Nissan",
+	"JT2": "Toyota",
+	"JTD": "Toyota",
+	"KMH": "Hyundai",
+	"KNA": "Kia",
+	"SAJ": "Jaguar",
+	"SAL": "Land Rover",
+	"SCC": "Lotus",
+	"TRU": "Audi (Hungary)",
+	"VF1": "Renault",
+	"VF3": "Peugeot",
+	"VSS": "SEAT",
+	"WAU": "Audi",
+	"WBA": "BMW",
+	"WDB": "Mercedes-Benz",
+	"WDD": "Mercedes-Benz",
+	"WP0": "Porsche",
+	"WVW": "Volkswagen",
+	"WV1": "Volkswagen (commercial)",
+	"YV1": "Volvo",
+	"ZAR": "Alfa Romeo",
+	"ZFA": "Fiat",
+	"ZFF": "Ferrari",
+}
+
+// vinCheckDigit computes the check digit of a VIN, 0-9 or X.
+func vinCheckDigit(vin string) byte {
+	sum := 0
+	for i, c := range vin {
+		v, ok := vinValues[c]
+		if !ok {
+			v = int(c - '0')
+		}
+		sum += v * vinWeights[i]
+	}
+	if sum%11 == 10 {
+		return 'X'
+	}
+	return byte('0' + sum%11)
+}
+
+// guessVIN recognizes 17 character vehicle identification numbers.  The
+// check digit is mandatory in North America only, so VINs with a wrong one
+// are still guessed, if not as confidently.
+func guessVIN(s string) []Guess {
+	if len(s) != 17 {
+		return nil
+	}
+	for _, c := range s {
+		if _, ok := vinValues[c]; !ok && (c < '0' || c > '9') {
+			return nil
+		}
+	}
+	if strings.Trim(s, "0123456789") == "" {
+		return nil
+	}
+
+	wmi := s[:3]
+	region := "unknown region"
+	for _, r := range vinRegions {
+		if s[0] >= r.from && s[0] <= r.to {
+			region = r.region
+		}
+	}
+	manufacturer := vinManufacturers[wmi]
+	if manufacturer == "" {
+		manufacturer = "unknown manufacturer"
+	}
+	additional := []string{fmt.Sprintf("world manufacturer identifier: %s (%s, %s)", wmi, manufacturer, region)}
+
+	if i := strings.IndexByte(vinYearCodes, s[9]); i >= 0 {
+		// North American passenger cars have a letter in position 7
+		// from 2010 on.
+		year, other := 1980+i, 2010+i
+		if s[6] < '0' || s[6] > '9' {
+			year, other = other, year
+		}
+		additional = append(additional, fmt.Sprintf("model year: %d (or %d)", year, other))
+	}
+	additional = append(additional, fmt.Sprintf("assembly plant: %c", s[10]))
+	additional = append(additional, fmt.Sprintf("serial number: %s", s[11:]))
+
+	g := Guess{
+		guess:      "Vehicle identification number " + s,
+		additional: additional,
+		source:     "VIN",
+		goodness:   150,
+	}
+	if want := vinCheckDigit(s); s[8] == want {
+		g.comment = "check digit OK"
+	} else {
+		g.comment = fmt.Sprintf("check digit %c is wrong, should be %c", s[8], want)
+		g.goodness = 20
+	}
+	return []Guess{g}
+}
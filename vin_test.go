@@ -0,0 +1,41 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGuessVIN(t *testing.T) {
+	gs := guessVIN("1M8GDM9AXKP042788")
+	if len(gs) != 1 {
+		t.Fatalf("guessVIN() = %+v, want one guess", gs)
+	}
+	want := []string{
+		"world manufacturer identifier: 1M8 (Motor Coach Industries, United States)",
+		"model year: 1989 (or 2019)",
+		"assembly plant: P",
+		"serial number: 042788",
+	}
+	if g := gs[0]; g.comment != "check digit OK" || g.goodness != 150 || !reflect.DeepEqual(g.additional, want) {
+		t.Errorf("guessVIN() = %+v", g)
+	}
+
+	gs = guessVIN("1M8GDM9A1KP042788")
+	if len(gs) != 1 || gs[0].comment != "check digit 1 is wrong, should be X" || gs[0].goodness != 20 {
+		t.Errorf("guessVIN() with wrong check digit = %+v", gs)
+	}
+
+	for _, s := range []string{"1M8GDM9AXKP04278", "1M8GDM9AXKP0427OO", "12345678901234567", "1m8gdm9axkp042788"} {
+		if gs := guessVIN(s); gs != nil {
+			t.Errorf("guessVIN(%q) = %+v, want nil", s, gs)
+		}
+	}
+}
+
+func TestVINCheckDigit(t *testing.T) {
+	for _, vin := range []string{"1M8GDM9AXKP042788", "11111111111111111"} {
+		if got := vinCheckDigit(vin); got != vin[8] {
+			t.Errorf("vinCheckDigit(%q) = %c, want %c", vin, got, vin[8])
+		}
+	}
+}